@@ -0,0 +1,126 @@
+package kvt
+
+import (
+	"encoding/json"
+	"sync"
+	"sync/atomic"
+)
+
+// AtomicStore is for the common case of one writer and many readers. Readers
+// never wait: each write makes a modified copy of the whole Store and then
+// atomically swaps it in. This makes writes O(n), so AtomicStore suits stores
+// that are read far more often than written. Concurrent writers are allowed
+// but are serialized. The zero value is an empty AtomicStore ready for use.
+type AtomicStore struct {
+	writeLock sync.Mutex
+	current   atomic.Value
+}
+
+// NewAtomicStore returns an AtomicStore starting with the contents of store,
+// which should no longer be used directly afterwards. If store is nil, a new
+// empty Store is used.
+func NewAtomicStore(store Store) *AtomicStore {
+	if store == nil {
+		store = Store{}
+	}
+	atomicStore := &AtomicStore{}
+	atomicStore.current.Store(store)
+	return atomicStore
+}
+
+// load returns the current Store, which must not be modified.
+func (atomicStore *AtomicStore) load() Store {
+	store, _ := atomicStore.current.Load().(Store)
+	return store
+}
+
+// write calls fn with a copy of the current Store and then makes that copy
+// the current Store.
+func (atomicStore *AtomicStore) write(fn func(store Store)) {
+	atomicStore.writeLock.Lock()
+	store := atomicStore.load().Copy()
+	fn(store)
+	atomicStore.current.Store(store)
+	atomicStore.writeLock.Unlock()
+}
+
+// Snapshot returns the current contents as a Store that will not change; it
+// is O(1) and must not be modified.
+func (atomicStore *AtomicStore) Snapshot() Store {
+	if store := atomicStore.load(); store != nil {
+		return store
+	}
+	return Store{}
+}
+
+// Get returns the value for a key; see Store.Get.
+func (atomicStore *AtomicStore) Get(key string) string {
+	return atomicStore.load().Get(key)
+}
+
+// Set is equivalent to SetTimestamped(key, value, Now()).
+func (atomicStore *AtomicStore) Set(key string, value string) {
+	atomicStore.write(func(store Store) { store.Set(key, value) })
+}
+
+// SetTimestamped stores the value for the key unless there is a newer entry;
+// see Store.SetTimestamped.
+func (atomicStore *AtomicStore) SetTimestamped(key string, value string, timestamp int64) {
+	atomicStore.write(func(store Store) { store.SetTimestamped(key, value, timestamp) })
+}
+
+// Delete is equivalent to DeleteTimestamped(key, Now()).
+func (atomicStore *AtomicStore) Delete(key string) {
+	atomicStore.write(func(store Store) { store.Delete(key) })
+}
+
+// DeleteTimestamped records a deletion marker for the key unless there is a
+// newer entry; see Store.DeleteTimestamped.
+func (atomicStore *AtomicStore) DeleteTimestamped(key string, timestamp int64) {
+	atomicStore.write(func(store Store) { store.DeleteTimestamped(key, timestamp) })
+}
+
+// Purge discards deletion markers older than the cutoff; see Store.Purge.
+func (atomicStore *AtomicStore) Purge(cutoff int64) {
+	atomicStore.write(func(store Store) { store.Purge(cutoff) })
+}
+
+// Absorb updates atomicStore with any newer entries from store2; see
+// Store.Absorb.
+func (atomicStore *AtomicStore) Absorb(store2 Store) {
+	atomicStore.write(func(store Store) { store.Absorb(store2) })
+}
+
+// Apply applies the batch if all the guards hold; see Store.Apply. Readers
+// will see either none or all of the batch.
+func (atomicStore *AtomicStore) Apply(batch *Batch, guards ...Guard) (err error) {
+	atomicStore.write(func(store Store) { err = store.Apply(batch, guards...) })
+	return err
+}
+
+// ModifiedSince returns a new Store of the entries newer than the timestamp;
+// see Store.ModifiedSince.
+func (atomicStore *AtomicStore) ModifiedSince(timestamp int64) Store {
+	return atomicStore.load().ModifiedSince(timestamp)
+}
+
+// Copy returns a new Store holding copies of all the entries; see Store.Copy.
+func (atomicStore *AtomicStore) Copy() Store {
+	return atomicStore.load().Copy()
+}
+
+// Hash returns a hash of the keys, timestamps, and flags; see Store.Hash.
+func (atomicStore *AtomicStore) Hash() string {
+	return atomicStore.load().Hash()
+}
+
+// MarshalJSON returns the JSON encoded version of the store or an error.
+func (atomicStore *AtomicStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(atomicStore.Snapshot())
+}
+
+// String returns the JSON encoded string representation of the store; see
+// Store.String.
+func (atomicStore *AtomicStore) String() string {
+	return atomicStore.Snapshot().String()
+}
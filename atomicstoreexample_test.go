@@ -0,0 +1,42 @@
+package kvt_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleAtomicStore() {
+	atomicStore := kvt.NewAtomicStore(nil)
+	atomicStore.SetTimestamped("config", "v1", 1)
+
+	// Readers never block, even while the writer is busy.
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			for j := 0; j < 100; j++ {
+				_ = atomicStore.Get("config")
+			}
+			wg.Done()
+		}()
+	}
+	atomicStore.SetTimestamped("config", "v2", 2)
+	wg.Wait()
+	fmt.Println(atomicStore)
+
+	// Output:
+	// {"config":["v2",2]}
+}
+
+func ExampleAtomicStore_Snapshot() {
+	atomicStore := &kvt.AtomicStore{}
+	atomicStore.SetTimestamped("A", "one", 1)
+	snapshot := atomicStore.Snapshot()
+	atomicStore.SetTimestamped("A", "two", 2)
+	fmt.Println(snapshot, atomicStore)
+
+	// Output:
+	// {"A":["one",1]} {"A":["two",2]}
+}
@@ -0,0 +1,75 @@
+package kvt
+
+import (
+	"sync"
+	"time"
+)
+
+// AutoSaver saves a SyncStore to a Backend whenever it has changed, but no more
+// often than once per interval; see SyncStore.StartAutoSave.
+type AutoSaver struct {
+	*background
+	syncStore *SyncStore
+	backend   Backend
+	lock      sync.Mutex
+	dirty     bool
+	err       error
+	// stopListening unregisters changed from the SyncStore.
+	stopListening func()
+}
+
+// StartAutoSave starts an AutoSaver that, every interval, saves a Snapshot of
+// the store to backend if any writes have changed it since the last save; as
+// with any Snapshot, the first write after each save copies the whole store. An
+// interval of 0 or less is treated as one second. Call Close on the AutoSaver
+// when it is no longer needed, which also saves any changes still pending; the
+// caller remains responsible for closing backend afterwards.
+func (syncStore *SyncStore) StartAutoSave(backend Backend, interval time.Duration) *AutoSaver {
+	autoSaver := &AutoSaver{syncStore: syncStore, backend: backend}
+	autoSaver.stopListening = syncStore.listen(&listener{changed: autoSaver.changed})
+	autoSaver.background = startBackground(interval, autoSaver.save)
+	return autoSaver
+}
+
+// changed is the SyncStore hook for each batch of changes.
+func (autoSaver *AutoSaver) changed(changes []Change) {
+	autoSaver.lock.Lock()
+	autoSaver.dirty = true
+	autoSaver.lock.Unlock()
+}
+
+// save saves the store if it has changed since the last save. If saving
+// fails, the store is left marked as changed so the next save tries again.
+func (autoSaver *AutoSaver) save() {
+	autoSaver.lock.Lock()
+	dirty := autoSaver.dirty
+	autoSaver.dirty = false
+	autoSaver.lock.Unlock()
+	if !dirty {
+		return
+	}
+	err := autoSaver.backend.Save(autoSaver.syncStore.Snapshot())
+	autoSaver.lock.Lock()
+	if err != nil {
+		autoSaver.dirty = true
+	}
+	autoSaver.err = err
+	autoSaver.lock.Unlock()
+}
+
+// Err returns the error from the most recent save, if it failed.
+func (autoSaver *AutoSaver) Err() error {
+	autoSaver.lock.Lock()
+	defer autoSaver.lock.Unlock()
+	return autoSaver.err
+}
+
+// Close stops the AutoSaver, first saving any pending changes, and returns
+// the error from the last save, if it failed. Changes made after Close are
+// not saved.
+func (autoSaver *AutoSaver) Close() error {
+	autoSaver.stopListening()
+	autoSaver.Stop()
+	autoSaver.save()
+	return autoSaver.Err()
+}
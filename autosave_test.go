@@ -0,0 +1,27 @@
+package kvt_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func TestAutoSaveDebounce(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	syncStore := kvt.NewSyncStore(nil)
+	autoSaver := syncStore.StartAutoSave(kvt.NewFileBackend(path), 10*time.Millisecond)
+	defer autoSaver.Close()
+	syncStore.SetTimestamped("A", "one", 1)
+	for deadline := time.Now().Add(5 * time.Second); ; {
+		store, err := kvt.LoadFile(path)
+		if err == nil && store.Get("A") == "one" {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal(store, err)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
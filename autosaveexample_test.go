@@ -0,0 +1,36 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore_StartAutoSave() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	syncStore := kvt.NewSyncStore(nil)
+	autoSaver := syncStore.StartAutoSave(kvt.NewFileBackend(path), time.Hour)
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.SetTimestamped("B", "two", 1)
+
+	// Nothing is saved until the interval passes, or the AutoSaver is
+	// closed.
+	_, err = kvt.LoadFile(path)
+	fmt.Println(os.IsNotExist(err))
+	fmt.Println(autoSaver.Close())
+	fmt.Println(kvt.LoadFile(path))
+
+	// Output:
+	// true
+	// <nil>
+	// {"A":["one",1],"B":["two",1]} <nil>
+}
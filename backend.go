@@ -0,0 +1,242 @@
+package kvt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+)
+
+// Backend is where a store is persisted, such as by a WAL or an AutoSaver. A
+// Backend holds a full copy of a store plus, optionally, deltas appended
+// since; Load returns them merged.
+type Backend interface {
+	// Load returns the saved store with any appended deltas absorbed into
+	// it, or an empty Store if nothing has been saved yet.
+	Load() (Store, error)
+	// Save replaces the saved store, discarding any appended deltas.
+	Save(store Store) error
+	// AppendDelta durably records entries to be absorbed into the saved
+	// store, without rewriting the whole store.
+	AppendDelta(delta Store) error
+	// Close releases any resources held by the Backend.
+	Close() error
+}
+
+// FileBackend is a Backend that saves the store to a JSON file, with SaveFile,
+// and appends deltas to a journal file beside it, path+".journal". The
+// journal holds one JSON encoded Store per line; since Absorb keeps whichever
+// entry is newest, replaying lines in any order, or more than once, gives the
+// same store.
+//
+// By default, each append to the journal is synced before AppendDelta
+// returns; see WithSync for other choices.
+type FileBackend struct {
+	path         string
+	syncMode     SyncMode
+	syncInterval time.Duration
+	lock         sync.Mutex
+	journal      *os.File
+	// dirty is set when the journal has appends that haven't been synced.
+	dirty bool
+	// syncer syncs the journal periodically with SyncOnInterval.
+	syncer *background
+	// syncErr is an error from syncer, to be returned by the next call.
+	syncErr error
+}
+
+var _ Backend = &FileBackend{}
+
+// NewFileBackend returns a FileBackend for the file at path. The WithSync and
+// WithSyncInterval options control when the journal is synced. A
+// SyncOnInterval interval of 0 or less means one second.
+func NewFileBackend(path string, opts ...FileBackendOption) *FileBackend {
+	o := &fileBackendOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.syncMode == SyncOnInterval && o.syncInterval <= 0 {
+		o.syncInterval = time.Second
+	}
+	return &FileBackend{path: path, syncMode: o.syncMode, syncInterval: o.syncInterval}
+}
+
+// Load returns the store saved at path with the journal, if any, absorbed
+// into it. A partially written last journal line, as left by a crash during
+// an append, is ignored.
+func (fileBackend *FileBackend) Load() (Store, error) {
+	fileBackend.lock.Lock()
+	defer fileBackend.lock.Unlock()
+	store, err := LoadFile(fileBackend.path)
+	if os.IsNotExist(err) {
+		store, err = Store{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	f, err := os.Open(fileBackend.path + ".journal")
+	if os.IsNotExist(err) {
+		return store, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	delta, _, err := replayJournal(f)
+	if err != nil {
+		return nil, fmt.Errorf("%s.journal: %s", fileBackend.path, err)
+	}
+	store.Absorb(delta)
+	return store, nil
+}
+
+// Save atomically writes the store to path, syncing the file and its
+// directory, and only then empties the journal. A crash in between loses
+// nothing, as the journal's entries are all in the store just saved.
+func (fileBackend *FileBackend) Save(store Store) error {
+	fileBackend.lock.Lock()
+	defer fileBackend.lock.Unlock()
+	if err := store.SaveFile(fileBackend.path); err != nil {
+		return err
+	}
+	if fileBackend.journal == nil {
+		if err := os.Truncate(fileBackend.path+".journal", 0); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	}
+	if err := fileBackend.journal.Truncate(0); err != nil {
+		return err
+	}
+	if _, err := fileBackend.journal.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+	fileBackend.dirty = false
+	return fileBackend.journal.Sync()
+}
+
+// AppendDelta appends the delta as a line to the journal, syncing it as set
+// by WithSync. If a background sync has failed since the last call, that
+// error is returned instead and the delta is not appended.
+func (fileBackend *FileBackend) AppendDelta(delta Store) error {
+	b, err := json.Marshal(delta)
+	if err != nil {
+		return err
+	}
+	fileBackend.lock.Lock()
+	defer fileBackend.lock.Unlock()
+	if err = fileBackend.syncErr; err != nil {
+		fileBackend.syncErr = nil
+		return err
+	}
+	if fileBackend.journal == nil {
+		if err = fileBackend.openJournal(); err != nil {
+			return err
+		}
+	}
+	if _, err = fileBackend.journal.Write(append(b, '\n')); err != nil {
+		return err
+	}
+	if fileBackend.syncMode != SyncEveryWrite {
+		fileBackend.dirty = true
+		return nil
+	}
+	return fileBackend.journal.Sync()
+}
+
+// syncDirty syncs the journal if it has unsynced appends; it is run
+// periodically with SyncOnInterval.
+func (fileBackend *FileBackend) syncDirty() {
+	fileBackend.lock.Lock()
+	defer fileBackend.lock.Unlock()
+	if !fileBackend.dirty || fileBackend.journal == nil {
+		return
+	}
+	if err := fileBackend.journal.Sync(); err != nil {
+		fileBackend.syncErr = err
+		return
+	}
+	fileBackend.dirty = false
+}
+
+// openJournal opens the journal for appending, first dropping any partially
+// written last line so the next append starts on a line of its own.
+func (fileBackend *FileBackend) openJournal() error {
+	path := fileBackend.path + ".journal"
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	_, size, err := replayJournal(f)
+	if err == nil {
+		err = f.Truncate(size)
+	}
+	if err == nil {
+		_, err = f.Seek(size, io.SeekStart)
+	}
+	if err != nil {
+		f.Close()
+		return fmt.Errorf("%s: %s", path, err)
+	}
+	fileBackend.journal = f
+	if fileBackend.syncMode == SyncOnInterval && fileBackend.syncer == nil {
+		fileBackend.syncer = startBackground(fileBackend.syncInterval, fileBackend.syncDirty)
+	}
+	return nil
+}
+
+// Close syncs any unsynced appends and closes the journal, if it was opened.
+func (fileBackend *FileBackend) Close() error {
+	fileBackend.lock.Lock()
+	syncer := fileBackend.syncer
+	fileBackend.syncer = nil
+	fileBackend.lock.Unlock()
+	if syncer != nil {
+		// Stopped without the lock held, as a sync in progress needs it.
+		syncer.Stop()
+	}
+	fileBackend.lock.Lock()
+	defer fileBackend.lock.Unlock()
+	err := fileBackend.syncErr
+	fileBackend.syncErr = nil
+	if fileBackend.journal == nil {
+		return err
+	}
+	if fileBackend.dirty {
+		if err2 := fileBackend.journal.Sync(); err == nil {
+			err = err2
+		}
+		fileBackend.dirty = false
+	}
+	if err2 := fileBackend.journal.Close(); err == nil {
+		err = err2
+	}
+	fileBackend.journal = nil
+	return err
+}
+
+// replayJournal returns the store recorded by the journal read from r and the
+// length of its complete lines.
+func replayJournal(r io.Reader) (Store, int64, error) {
+	store := Store{}
+	reader := bufio.NewReader(r)
+	var size int64
+	for line := 1; ; line++ {
+		b, err := reader.ReadBytes('\n')
+		if err == io.EOF {
+			return store, size, nil
+		}
+		if err != nil {
+			return nil, 0, err
+		}
+		store2 := Store{}
+		if err = json.Unmarshal(b, &store2); err != nil {
+			return nil, 0, fmt.Errorf("invalid journal line %d: %s", line, err)
+		}
+		store.Absorb(store2)
+		size += int64(len(b))
+	}
+}
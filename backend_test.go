@@ -0,0 +1,40 @@
+package kvt_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func TestFileBackendSyncModes(t *testing.T) {
+	for name, opts := range map[string][]kvt.FileBackendOption{
+		"EveryWrite":   nil,
+		"OnInterval":   {kvt.WithSyncInterval(time.Millisecond)},
+		"ZeroInterval": {kvt.WithSyncInterval(0)},
+		"OnClose":      {kvt.WithSync(kvt.SyncOnClose)},
+	} {
+		t.Run(name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "store.json")
+			backend := kvt.NewFileBackend(path, opts...)
+			for i := int64(1); i <= 3; i++ {
+				if err := backend.AppendDelta(kvt.Store{"A": {Timestamp: i}}); err != nil {
+					t.Fatal(err)
+				}
+				time.Sleep(2 * time.Millisecond)
+			}
+			if err := backend.Close(); err != nil {
+				t.Fatal(err)
+			}
+			// Closing twice is harmless.
+			if err := backend.Close(); err != nil {
+				t.Fatal(err)
+			}
+			store, err := kvt.NewFileBackend(path).Load()
+			if err != nil || store.String() != `{"A":[null,3]}` {
+				t.Fatal(store, err)
+			}
+		})
+	}
+}
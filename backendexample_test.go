@@ -0,0 +1,54 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+// memoryBackend is a minimal Backend that keeps everything in memory, as a
+// starting point for Backends over databases or object stores.
+type memoryBackend struct {
+	saved  kvt.Store
+	deltas []kvt.Store
+}
+
+func (backend *memoryBackend) Load() (kvt.Store, error) {
+	store := backend.saved.Copy()
+	store.AbsorbAll(backend.deltas...)
+	return store, nil
+}
+
+func (backend *memoryBackend) Save(store kvt.Store) error {
+	backend.saved = store.Copy()
+	backend.deltas = nil
+	return nil
+}
+
+func (backend *memoryBackend) AppendDelta(delta kvt.Store) error {
+	backend.deltas = append(backend.deltas, delta.Copy())
+	return nil
+}
+
+func (backend *memoryBackend) Close() error {
+	return nil
+}
+
+func ExampleBackend() {
+	backend := &memoryBackend{}
+	syncStore := kvt.NewSyncStore(nil)
+	wal, err := syncStore.OpenWAL(backend)
+	if err != nil {
+		panic(err)
+	}
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.SetTimestamped("B", "two", 1)
+	fmt.Println(len(backend.deltas), backend.saved)
+	wal.Compact()
+	fmt.Println(len(backend.deltas), backend.saved)
+	wal.Close()
+
+	// Output:
+	// 2 null
+	// 0 {"A":["one",1],"B":["two",1]}
+}
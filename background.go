@@ -0,0 +1,48 @@
+package kvt
+
+import (
+	"sync"
+	"time"
+)
+
+// background runs a function periodically in its own goroutine until
+// stopped.
+type background struct {
+	stop chan struct{}
+	done chan struct{}
+	once sync.Once
+}
+
+// defaultInterval is used in place of an interval of 0 or less, which
+// time.NewTicker would reject.
+const defaultInterval = time.Second
+
+// startBackground calls fn every interval, or every defaultInterval if
+// interval is 0 or less, until Stop is called.
+func startBackground(interval time.Duration, fn func()) *background {
+	if interval <= 0 {
+		interval = defaultInterval
+	}
+	b := &background{stop: make(chan struct{}), done: make(chan struct{})}
+	go func() {
+		defer close(b.done)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				fn()
+			case <-b.stop:
+				return
+			}
+		}
+	}()
+	return b
+}
+
+// Stop stops the background task, waiting for any run in progress to finish.
+// It is safe to call Stop more than once.
+func (b *background) Stop() {
+	b.once.Do(func() { close(b.stop) })
+	<-b.done
+}
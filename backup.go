@@ -0,0 +1,191 @@
+package kvt
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// ErrDeltasUnsupported is returned by the AppendDelta method of Backends that
+// only save whole snapshots, such as Backups; those are meant to be used as
+// the Backend of an AutoSaver rather than of a WAL.
+var ErrDeltasUnsupported = errors.New("kvt: appending deltas is not supported")
+
+// Backups keeps numbered generations of backups of a store in a directory,
+// for point-in-time recovery. Each backup is a checksummed snapshot, as
+// written by SaveFileChecksummed, so damaged backups are detected on Restore.
+//
+// Backups is also a Backend, so SyncStore.StartAutoSave can write a new
+// generation periodically, whenever the store has changed, with older
+// generations rotated out as set by NewBackups and SetKeepDaily.
+type Backups struct {
+	dir       string
+	keep      int
+	keepDaily int
+}
+
+var _ Backend = &Backups{}
+
+// Backup describes one backup generation.
+type Backup struct {
+	Generation uint64
+	// Time is when the backup was written.
+	Time time.Time
+	// Path is the backup's file.
+	Path string
+}
+
+// NewBackups returns Backups for the directory at dir, which will be created
+// if needed, keeping the newest keep generations; keep less than 1 keeps all
+// of them.
+func NewBackups(dir string, keep int) *Backups {
+	return &Backups{dir: dir, keep: keep}
+}
+
+// List returns the backups in the directory, oldest first.
+func (backups *Backups) List() ([]*Backup, error) {
+	entries, err := os.ReadDir(backups.dir)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var list []*Backup
+	for _, entry := range entries {
+		var generation uint64
+		var timestamp int64
+		if _, err := fmt.Sscanf(entry.Name(), "backup-%d-%d.kvt", &generation, &timestamp); err != nil {
+			continue
+		}
+		if entry.Name() != backupName(generation, timestamp) {
+			continue
+		}
+		list = append(list, &Backup{Generation: generation, Time: Time(timestamp), Path: filepath.Join(backups.dir, entry.Name())})
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Generation < list[j].Generation })
+	return list, nil
+}
+
+// backupName returns the file name for a backup.
+func backupName(generation uint64, timestamp int64) string {
+	return fmt.Sprintf("backup-%010d-%d.kvt", generation, timestamp)
+}
+
+// Write saves the store as the next generation, after the newest existing
+// one, and then removes the oldest generations beyond those to keep. It
+// returns the new backup.
+func (backups *Backups) Write(store Store) (*Backup, error) {
+	if err := os.MkdirAll(backups.dir, 0777); err != nil {
+		return nil, err
+	}
+	list, err := backups.List()
+	if err != nil {
+		return nil, err
+	}
+	var generation uint64 = 1
+	if len(list) > 0 {
+		generation = list[len(list)-1].Generation + 1
+	}
+	timestamp := Now()
+	backup := &Backup{Generation: generation, Time: Time(timestamp), Path: filepath.Join(backups.dir, backupName(generation, timestamp))}
+	if err = store.SaveFileChecksummed(backup.Path); err != nil {
+		return nil, err
+	}
+	return backup, backups.prune(append(list, backup))
+}
+
+// SetKeepDaily has Backups also keep the newest generation from each of the
+// most recent days days, by UTC date, that have backups, even when they are
+// older than the generations kept by number; 0, the default, disables this.
+// For example, NewBackups(dir, 24) with SetKeepDaily(30), writing hourly,
+// keeps a day's worth of hourly backups and a month of daily ones.
+func (backups *Backups) SetKeepDaily(days int) {
+	backups.keepDaily = days
+}
+
+// Prune removes the generations that are no longer to be kept. Write does
+// this after each backup; Prune is only needed after lowering the retention.
+func (backups *Backups) Prune() error {
+	list, err := backups.List()
+	if err != nil {
+		return err
+	}
+	return backups.prune(list)
+}
+
+// prune removes the backups in list, oldest first, that are no longer to be
+// kept.
+func (backups *Backups) prune(list []*Backup) error {
+	if backups.keep < 1 {
+		return nil
+	}
+	keep := map[*Backup]bool{}
+	for i := len(list) - 1; i >= 0 && i >= len(list)-backups.keep; i-- {
+		keep[list[i]] = true
+	}
+	days := 0
+	var lastDay string
+	for i := len(list) - 1; i >= 0 && days < backups.keepDaily; i-- {
+		if day := list[i].Time.UTC().Format("2006-01-02"); day != lastDay {
+			keep[list[i]] = true
+			lastDay = day
+			days++
+		}
+	}
+	for _, backup := range list {
+		if keep[backup] {
+			continue
+		}
+		if err := os.Remove(backup.Path); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// Restore returns the store saved as the generation given.
+func (backups *Backups) Restore(generation uint64) (Store, error) {
+	list, err := backups.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, backup := range list {
+		if backup.Generation == generation {
+			return LoadFile(backup.Path)
+		}
+	}
+	return nil, fmt.Errorf("kvt: no backup generation %d in %s", generation, backups.dir)
+}
+
+// Load returns the store saved as the newest generation, or an empty Store if
+// there are no backups.
+func (backups *Backups) Load() (Store, error) {
+	list, err := backups.List()
+	if err != nil {
+		return nil, err
+	}
+	if len(list) == 0 {
+		return Store{}, nil
+	}
+	return LoadFile(list[len(list)-1].Path)
+}
+
+// Save writes the store as the next generation; see Write.
+func (backups *Backups) Save(store Store) error {
+	_, err := backups.Write(store)
+	return err
+}
+
+// AppendDelta returns ErrDeltasUnsupported.
+func (backups *Backups) AppendDelta(delta Store) error {
+	return ErrDeltasUnsupported
+}
+
+// Close does nothing.
+func (backups *Backups) Close() error {
+	return nil
+}
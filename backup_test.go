@@ -0,0 +1,52 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func TestBackupsKeepDaily(t *testing.T) {
+	dir := t.TempDir()
+	start := time.Date(2020, 10, 1, 0, 0, 0, 0, time.UTC)
+	// Three backups a day, eight hours apart, for five days.
+	for generation := 1; generation <= 15; generation++ {
+		timestamp := kvt.Timestamp(start.Add(time.Duration(generation-1) * 8 * time.Hour))
+		name := fmt.Sprintf("backup-%010d-%d.kvt", generation, timestamp)
+		if err := (kvt.Store{}).SaveFileChecksummed(filepath.Join(dir, name)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	backups := kvt.NewBackups(dir, 2)
+	backups.SetKeepDaily(3)
+	if err := backups.Prune(); err != nil {
+		t.Fatal(err)
+	}
+	list, err := backups.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var generations []uint64
+	for _, backup := range list {
+		generations = append(generations, backup.Generation)
+	}
+	// The last two, plus the newest of each of the last three days.
+	if want := []uint64{9, 12, 14, 15}; !reflect.DeepEqual(generations, want) {
+		t.Fatal(generations, want)
+	}
+
+	if err = backups.AppendDelta(kvt.Store{}); err != kvt.ErrDeltasUnsupported {
+		t.Fatal(err)
+	}
+	if store, err := kvt.NewBackups(filepath.Join(dir, "none"), 1).Load(); err != nil || len(store) != 0 {
+		t.Fatal(store, err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "none")); !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+}
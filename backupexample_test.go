@@ -0,0 +1,70 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleBackups() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	backups := kvt.NewBackups(dir, 2)
+
+	store := kvt.Store{}
+	for i, value := range []string{"one", "two", "three"} {
+		store.SetTimestamped("A", value, int64(i+1))
+		backup, err := backups.Write(store)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println("wrote generation", backup.Generation)
+	}
+	list, _ := backups.List()
+	for _, backup := range list {
+		fmt.Println("have generation", backup.Generation)
+	}
+	fmt.Println(backups.Restore(2))
+	_, err = backups.Restore(1)
+	fmt.Println(err != nil)
+
+	// Output:
+	// wrote generation 1
+	// wrote generation 2
+	// wrote generation 3
+	// have generation 2
+	// have generation 3
+	// {"A":["two",2]} <nil>
+	// true
+}
+
+func ExampleBackups_SetKeepDaily() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	// Keep the last 24 generations and one per day for a week.
+	backups := kvt.NewBackups(dir, 24)
+	backups.SetKeepDaily(7)
+
+	// As a Backend, an AutoSaver writes a new generation each interval in
+	// which the store changed, and once more when closed.
+	syncStore := kvt.NewSyncStore(nil)
+	autoSaver := syncStore.StartAutoSave(backups, time.Hour)
+	syncStore.SetTimestamped("A", "one", 1)
+	fmt.Println(autoSaver.Close())
+	list, _ := backups.List()
+	fmt.Println(len(list))
+	fmt.Println(backups.Load())
+
+	// Output:
+	// <nil>
+	// 1
+	// {"A":["one",1]} <nil>
+}
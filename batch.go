@@ -0,0 +1,130 @@
+package kvt
+
+import "fmt"
+
+// Batch is a list of Set and Delete operations to be applied to a store
+// together with Store.Apply.
+type Batch struct {
+	ops []batchOp
+}
+
+type batchOp struct {
+	key   string
+	value *string
+	// now is set for operations that are to use the time of the Apply, in
+	// place of timestamp.
+	now       bool
+	timestamp int64
+}
+
+// Set queues a Set for the key; all such untimestamped operations in the
+// batch will share the same timestamp when applied.
+func (batch *Batch) Set(key string, value string) {
+	batch.ops = append(batch.ops, batchOp{key: key, value: &value, now: true})
+}
+
+// SetTimestamped queues a SetTimestamped for the key.
+func (batch *Batch) SetTimestamped(key string, value string, timestamp int64) {
+	batch.ops = append(batch.ops, batchOp{key: key, value: &value, timestamp: timestamp})
+}
+
+// Delete queues a Delete for the key; all such untimestamped operations in
+// the batch will share the same timestamp when applied.
+func (batch *Batch) Delete(key string) {
+	batch.ops = append(batch.ops, batchOp{key: key, now: true})
+}
+
+// DeleteTimestamped queues a DeleteTimestamped for the key.
+func (batch *Batch) DeleteTimestamped(key string, timestamp int64) {
+	batch.ops = append(batch.ops, batchOp{key: key, timestamp: timestamp})
+}
+
+// Len returns the number of operations queued in the batch.
+func (batch *Batch) Len() int {
+	return len(batch.ops)
+}
+
+// keys returns the keys of the operations queued in the batch.
+func (batch *Batch) keys() []string {
+	ks := make([]string, len(batch.ops))
+	for i, op := range batch.ops {
+		ks[i] = op.key
+	}
+	return ks
+}
+
+// Guard is a condition checked by Store.Apply before applying a batch.
+type Guard struct {
+	Key string
+	// Timestamp is what the key's entry's timestamp must be, unless Absent
+	// is set.
+	Timestamp int64
+	// Absent means the key must have no entry at all.
+	Absent bool
+}
+
+// Apply checks each of the guards and, only if all hold, applies every
+// operation in the batch. Operations queued without timestamps all use the
+// same timestamp. An error describing the first failed guard is returned if
+// the batch was not applied.
+func (store Store) Apply(batch *Batch, guards ...Guard) error {
+	for _, guard := range guards {
+		valueTimestamp := store[guard.Key]
+		switch {
+		case guard.Absent && valueTimestamp != nil:
+			return fmt.Errorf("guard failed for key %q: timestamp is %d, expected no entry", guard.Key, valueTimestamp.Timestamp)
+		case guard.Absent:
+		case valueTimestamp == nil:
+			return fmt.Errorf("guard failed for key %q: no entry, expected timestamp %d", guard.Key, guard.Timestamp)
+		case valueTimestamp.Timestamp != guard.Timestamp:
+			return fmt.Errorf("guard failed for key %q: timestamp is %d, expected %d", guard.Key, valueTimestamp.Timestamp, guard.Timestamp)
+		}
+	}
+	now := Now()
+	for _, op := range batch.ops {
+		timestamp := op.timestamp
+		if op.now {
+			timestamp = now
+		}
+		if op.value == nil {
+			store.DeleteTimestamped(op.key, timestamp)
+		} else {
+			store.SetTimestamped(op.key, *op.value, timestamp)
+		}
+	}
+	return nil
+}
+
+// Txn stages operations for a SyncStore which are then applied together by
+// Commit; concurrent readers of the SyncStore see either none or all of them.
+type Txn struct {
+	Batch
+	syncStore *SyncStore
+	guards    []Guard
+}
+
+// Begin returns a new Txn for syncStore.
+func (syncStore *SyncStore) Begin() *Txn {
+	return &Txn{syncStore: syncStore}
+}
+
+// Guard adds a condition that the key's entry must have the timestamp given
+// for Commit to succeed.
+func (txn *Txn) Guard(key string, timestamp int64) {
+	txn.guards = append(txn.guards, Guard{Key: key, Timestamp: timestamp})
+}
+
+// GuardAbsent adds a condition that the key must have no entry at all for
+// Commit to succeed.
+func (txn *Txn) GuardAbsent(key string) {
+	txn.guards = append(txn.guards, Guard{Key: key, Absent: true})
+}
+
+// Commit applies the staged operations if all the guards hold; see
+// Store.Apply. Either way, the Txn is empty afterwards and may be reused.
+func (txn *Txn) Commit() error {
+	err := txn.syncStore.Apply(&txn.Batch, txn.guards...)
+	txn.Batch = Batch{}
+	txn.guards = nil
+	return err
+}
@@ -0,0 +1,26 @@
+package kvt_test
+
+import (
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestApplyTimestampZero(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 0)
+	batch := &kvt.Batch{}
+	batch.SetTimestamped("B", "two", 0)
+	batch.DeleteTimestamped("C", 0)
+	if err := store.Apply(batch, kvt.Guard{Key: "A", Timestamp: 0}, kvt.Guard{Key: "B", Absent: true}); err != nil {
+		t.Fatal(err)
+	}
+	if s := store.String(); s != `{"A":["one",0],"B":["two",0],"C":[null,0]}` {
+		t.Fatal(s)
+	}
+	for _, guard := range []kvt.Guard{{Key: "A", Absent: true}, {Key: "D", Timestamp: 0}} {
+		if err := store.Apply(&kvt.Batch{}, guard); err == nil {
+			t.Errorf("expected %#v to fail", guard)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleBatch() {
+	store := kvt.Store{}
+	batch := &kvt.Batch{}
+	batch.Set("version", "2")
+	batch.Set("checksum", "abcd")
+	batch.Delete("legacy")
+	fmt.Println(batch.Len(), store.Apply(batch))
+	fmt.Println(store.SimpleString())
+	fmt.Println(store["version"].Timestamp == store["checksum"].Timestamp)
+
+	// Output:
+	// 3 <nil>
+	// checksum=abcd,legacy/deleted,version=2
+	// true
+}
+
+func ExampleStore_Apply() {
+	store := kvt.Store{}
+	store.SetTimestamped("version", "1", 10)
+	store.SetTimestamped("checksum", "1234", 10)
+
+	// Only update version and checksum together if nobody else has changed
+	// them since we last looked.
+	batch := &kvt.Batch{}
+	batch.SetTimestamped("version", "2", 20)
+	batch.SetTimestamped("checksum", "abcd", 20)
+	guards := []kvt.Guard{{Key: "version", Timestamp: 10}, {Key: "checksum", Timestamp: 10}}
+	fmt.Println(store.Apply(batch, guards...))
+	fmt.Println(store)
+
+	// A second attempt with the same guards fails and changes nothing.
+	batch = &kvt.Batch{}
+	batch.SetTimestamped("version", "3", 30)
+	fmt.Println(store.Apply(batch, guards...))
+	fmt.Println(store)
+
+	// Output:
+	// <nil>
+	// {"checksum":["abcd",20],"version":["2",20]}
+	// guard failed for key "version": timestamp is 20, expected 10
+	// {"checksum":["abcd",20],"version":["2",20]}
+}
+
+func ExampleTxn() {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.SetTimestamped("version", "1", 10)
+
+	txn := syncStore.Begin()
+	txn.Guard("version", 10)
+	txn.Set("version", "2")
+	txn.Set("checksum", "abcd")
+	fmt.Println(txn.Commit())
+	fmt.Println(syncStore.SimpleString())
+
+	txn.Guard("version", 10)
+	txn.Set("version", "3")
+	fmt.Println(txn.Commit() != nil, syncStore.Get("version"))
+
+	// Output:
+	// <nil>
+	// checksum=abcd,version=2
+	// true 2
+}
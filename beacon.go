@@ -0,0 +1,107 @@
+package kvt
+
+import (
+	"encoding/json"
+	"os"
+	"sync"
+)
+
+// HashBeaconState is the content of a hash beacon file, as JSON such as
+// {"sequence":3,"hash":"3d6a6976bcf5dfb9"}.
+type HashBeaconState struct {
+	// Sequence starts at 1 when the beacon is created and goes up by one with
+	// each change written.
+	Sequence uint64 `json:"sequence"`
+	// Hash is the store's Hash at the time.
+	Hash string `json:"hash"`
+}
+
+// HashBeacon keeps a small file up to date with a SyncStore's hash, so other
+// processes can tell the store has changed by watching just that file; see
+// SyncStore.StartHashBeacon.
+type HashBeacon struct {
+	syncStore *SyncStore
+	path      string
+	lock      sync.Mutex
+	sequence  uint64
+	closed    bool
+	err       error
+	// stopListening unregisters the beacon from the SyncStore.
+	stopListening func()
+}
+
+// StartHashBeacon writes a HashBeaconState to the file at path and rewrites it
+// after every write to the SyncStore that changes anything, and after every
+// Purge, always replacing the file atomically. Each rewrite computes the full
+// Hash, so this is best suited to stores that are not written to at a high
+// rate.
+func (syncStore *SyncStore) StartHashBeacon(path string) (*HashBeacon, error) {
+	hashBeacon := &HashBeacon{syncStore: syncStore, path: path}
+	// Listen first, so no write goes unreflected; any change reported before
+	// the initial write finishes waits on the lock and then writes again.
+	hashBeacon.lock.Lock()
+	hashBeacon.stopListening = syncStore.listen(&listener{changed: hashBeacon.changed, purged: hashBeacon.purged})
+	err := hashBeacon.write()
+	if err != nil {
+		hashBeacon.closed = true
+	}
+	hashBeacon.lock.Unlock()
+	if err != nil {
+		hashBeacon.stopListening()
+		return nil, err
+	}
+	return hashBeacon, nil
+}
+
+// changed is the SyncStore hook for each batch of changes.
+func (hashBeacon *HashBeacon) changed(changes []Change) {
+	hashBeacon.update()
+}
+
+// purged is the SyncStore hook for each Purge, which may discard deletion
+// markers and so change the Hash without reporting any changes.
+func (hashBeacon *HashBeacon) purged(cutoff int64) {
+	hashBeacon.update()
+}
+
+// update writes the next state to the file unless the beacon is closed.
+func (hashBeacon *HashBeacon) update() {
+	hashBeacon.lock.Lock()
+	if !hashBeacon.closed {
+		if err := hashBeacon.write(); err != nil && hashBeacon.err == nil {
+			hashBeacon.err = err
+		}
+	}
+	hashBeacon.lock.Unlock()
+}
+
+// write writes the next state to the file; the lock must be held.
+func (hashBeacon *HashBeacon) write() error {
+	hashBeacon.sequence++
+	b, err := json.Marshal(HashBeaconState{Sequence: hashBeacon.sequence, Hash: hashBeacon.syncStore.Hash()})
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(hashBeacon.path, append(b, '\n'))
+}
+
+// Close stops further updates to the file, which is left in place, and
+// returns the first error encountered while updating it, if any.
+func (hashBeacon *HashBeacon) Close() error {
+	hashBeacon.stopListening()
+	hashBeacon.lock.Lock()
+	defer hashBeacon.lock.Unlock()
+	hashBeacon.closed = true
+	return hashBeacon.err
+}
+
+// ReadHashBeacon reads the HashBeaconState from the file at path.
+func ReadHashBeacon(path string) (HashBeaconState, error) {
+	var state HashBeaconState
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return state, err
+	}
+	err = json.Unmarshal(b, &state)
+	return state, err
+}
@@ -0,0 +1,56 @@
+package kvt_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestHashBeaconPurgeAndClose(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "beacon")
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.DeleteTimestamped("A", 1)
+	hashBeacon, err := syncStore.StartHashBeacon(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncStore.Purge(10)
+	state, err := kvt.ReadHashBeacon(path)
+	if err != nil || state.Hash != syncStore.Hash() || state.Sequence != 2 {
+		t.Fatal(state, err, syncStore.Hash())
+	}
+	if err = hashBeacon.Close(); err != nil {
+		t.Fatal(err)
+	}
+	syncStore.SetTimestamped("B", "two", 2)
+	if state2, err := kvt.ReadHashBeacon(path); err != nil || state2 != state {
+		t.Fatal(state2, err)
+	}
+}
+
+func TestHashBeaconConcurrentStart(t *testing.T) {
+	for i := 0; i < 20; i++ {
+		path := filepath.Join(t.TempDir(), "beacon")
+		syncStore := kvt.NewSyncStore(nil)
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				syncStore.Set(fmt.Sprint(j), "value")
+			}
+		}()
+		hashBeacon, err := syncStore.StartHashBeacon(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		wg.Wait()
+		if state, err := kvt.ReadHashBeacon(path); err != nil || state.Hash != syncStore.Hash() {
+			t.Fatal(state, err, syncStore.Hash())
+		}
+		hashBeacon.Close()
+	}
+}
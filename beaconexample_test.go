@@ -0,0 +1,37 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore_StartHashBeacon() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.hash")
+
+	syncStore := kvt.NewSyncStore(nil)
+	hashBeacon, err := syncStore.StartHashBeacon(path)
+	if err != nil {
+		panic(err)
+	}
+	syncStore.SetTimestamped("A", "one", 1)
+	// Writes that change nothing don't update the file.
+	syncStore.SetTimestamped("A", "old", 0)
+	b, _ := os.ReadFile(path)
+	fmt.Print(string(b))
+	state, err := kvt.ReadHashBeacon(path)
+	fmt.Println(state.Sequence, state.Hash == syncStore.Hash(), err)
+	fmt.Println(hashBeacon.Close())
+
+	// Output:
+	// {"sequence":2,"hash":"7816aa8cb7c88f29"}
+	// 2 true <nil>
+	// <nil>
+}
@@ -0,0 +1,130 @@
+package kvt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+// binaryVersion is the first byte of the encoding written by MarshalBinary.
+const binaryVersion = 1
+
+// errBinaryTruncated is returned by UnmarshalBinary when the data ends early.
+var errBinaryTruncated = errors.New("kvt: truncated binary store")
+
+// MarshalBinary returns a compact binary encoding of the store, implementing
+// encoding.BinaryMarshaler. It is a version byte and the number of entries,
+// followed by each entry in key order: the key's length and bytes, the
+// timestamp, a header holding the flags shifted left one bit with the low
+// bit set for a deletion marker, and then, unless deleted, the value's length
+// and bytes. Lengths, the count, and the header are uvarints; the timestamp
+// is a varint.
+func (store Store) MarshalBinary() ([]byte, error) {
+	b := make([]byte, 0, 1+binary.MaxVarintLen64+len(store)*16)
+	b = append(b, binaryVersion)
+	b = binary.AppendUvarint(b, uint64(len(store)))
+	for _, key := range store.sortedKeys() {
+		valueTimestamp := store[key]
+		b = binary.AppendUvarint(b, uint64(len(key)))
+		b = append(b, key...)
+		b = binary.AppendVarint(b, valueTimestamp.Timestamp)
+		header := uint64(valueTimestamp.Flags) << 1
+		if valueTimestamp.Value == nil {
+			b = binary.AppendUvarint(b, header|1)
+			continue
+		}
+		b = binary.AppendUvarint(b, header)
+		b = binary.AppendUvarint(b, uint64(len(*valueTimestamp.Value)))
+		b = append(b, *valueTimestamp.Value...)
+	}
+	return b, nil
+}
+
+// UnmarshalBinary replaces the store with the one encoded in b by
+// MarshalBinary, implementing encoding.BinaryUnmarshaler.
+func (store *Store) UnmarshalBinary(b []byte) error {
+	if len(b) == 0 {
+		return errBinaryTruncated
+	}
+	if b[0] != binaryVersion {
+		return fmt.Errorf("kvt: unknown binary store version %d", b[0])
+	}
+	reader := &binaryReader{b: b[1:]}
+	count := reader.uvarint()
+	if reader.err == nil && count > uint64(len(reader.b)) {
+		// Each entry takes at least a few bytes, so this count is a lie;
+		// checked to avoid a huge allocation.
+		reader.err = errBinaryTruncated
+	}
+	store2 := make(Store, count)
+	for i := uint64(0); i < count && reader.err == nil; i++ {
+		key := reader.bytes()
+		valueTimestamp := &ValueTimestamp{Timestamp: reader.varint()}
+		header := reader.uvarint()
+		if header>>1 > uint64(^uint32(0)) {
+			return errors.New("kvt: invalid flags in binary store")
+		}
+		valueTimestamp.Flags = Flags(header >> 1)
+		if header&1 == 0 {
+			value := string(reader.bytes())
+			valueTimestamp.Value = &value
+		}
+		store2[string(key)] = valueTimestamp
+	}
+	if reader.err != nil {
+		return reader.err
+	}
+	if len(reader.b) != 0 {
+		return fmt.Errorf("kvt: %d extra bytes after binary store", len(reader.b))
+	}
+	*store = store2
+	return nil
+}
+
+// binaryReader decodes the parts of a binary store in turn, recording the
+// first error and returning zero values after it.
+type binaryReader struct {
+	b   []byte
+	err error
+}
+
+func (reader *binaryReader) uvarint() uint64 {
+	if reader.err != nil {
+		return 0
+	}
+	v, n := binary.Uvarint(reader.b)
+	if n <= 0 {
+		reader.err = errBinaryTruncated
+		return 0
+	}
+	reader.b = reader.b[n:]
+	return v
+}
+
+func (reader *binaryReader) varint() int64 {
+	if reader.err != nil {
+		return 0
+	}
+	v, n := binary.Varint(reader.b)
+	if n <= 0 {
+		reader.err = errBinaryTruncated
+		return 0
+	}
+	reader.b = reader.b[n:]
+	return v
+}
+
+// bytes returns the next length prefixed bytes.
+func (reader *binaryReader) bytes() []byte {
+	length := reader.uvarint()
+	if reader.err != nil {
+		return nil
+	}
+	if length > uint64(len(reader.b)) {
+		reader.err = errBinaryTruncated
+		return nil
+	}
+	b := reader.b[:length]
+	reader.b = reader.b[length:]
+	return b
+}
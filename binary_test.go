@@ -0,0 +1,79 @@
+package kvt_test
+
+import (
+	"encoding"
+	"math"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+var (
+	_ encoding.BinaryMarshaler   = kvt.Store{}
+	_ encoding.BinaryUnmarshaler = &kvt.Store{}
+)
+
+func TestBinaryRoundTrip(t *testing.T) {
+	for _, store := range []kvt.Store{
+		{},
+		{"": {Value: new(string)}},
+	} {
+		testBinaryRoundTrip(t, store)
+	}
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.SetTimestamped("B", "two\x00\xff", -1602000000123456789)
+	store.SetTimestamped("C", "", math.MaxInt64)
+	store.DeleteTimestamped("D", math.MinInt64)
+	store.DeleteTimestamped("E", 0)
+	store["E"].Flags = kvt.FlagPinned
+	store["A"].Flags = kvt.Flags(math.MaxUint32)
+	testBinaryRoundTrip(t, store)
+}
+
+func testBinaryRoundTrip(t *testing.T, store kvt.Store) {
+	b, err := store.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	loaded := kvt.Store{"stale": {}}
+	if err = loaded.UnmarshalBinary(b); err != nil {
+		t.Fatal(err)
+	}
+	if len(loaded) != len(store) {
+		t.Fatal(loaded, store)
+	}
+	for key, valueTimestamp := range store {
+		if a, b := loaded[key].String(), valueTimestamp.String(); a != b {
+			t.Fatal(key, a, b)
+		}
+	}
+	// Every truncation is detected.
+	for i := 0; i < len(b); i++ {
+		if err = loaded.UnmarshalBinary(b[:i]); err == nil {
+			t.Fatal("no error truncated to", i, "of", len(b))
+		}
+	}
+}
+
+func TestBinaryJunk(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	b, err := store.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, junk := range [][]byte{
+		{2, 0},
+		append(b, 0),
+		{1, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f},
+		{1, 1, 1, 'A', 2, 0xff, 0xff, 0xff, 0xff, 0x7f},
+	} {
+		if err = store.UnmarshalBinary(junk); err == nil {
+			t.Errorf("expected error from %v", junk)
+		}
+	}
+	if a, b := store.String(), `{"A":["one",1]}`; a != b {
+		t.Fatal("store changed by failed unmarshal", a)
+	}
+}
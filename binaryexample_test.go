@@ -0,0 +1,26 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_MarshalBinary() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	b, err := store.MarshalBinary()
+	fmt.Printf("%d bytes %v\n", len(b), err)
+	fmt.Printf("%d bytes as JSON\n", len(store.String()))
+
+	var store2 kvt.Store
+	fmt.Println(store2.UnmarshalBinary(b))
+	fmt.Println(store2)
+
+	// Output:
+	// 14 bytes <nil>
+	// 28 bytes as JSON
+	// <nil>
+	// {"A":["one",1],"B":[null,2]}
+}
@@ -0,0 +1,71 @@
+package kvt
+
+import (
+	"strings"
+	"sync"
+	"time"
+)
+
+// Bridge shares the keys under one prefix of a store with the keys under
+// another prefix of a second store, in both directions. For example, bridging
+// "clusterA/" in one store with "shared/" in another makes "clusterA/x" and
+// "shared/x" converge, while the rest of each store is left alone.
+type Bridge struct {
+	a       Storer
+	prefixA string
+	b       Storer
+	prefixB string
+
+	lock   sync.Mutex
+	runner *background
+}
+
+// NewBridge returns a Bridge between the keys under prefixA in a and those
+// under prefixB in b.
+func NewBridge(a Storer, prefixA string, b Storer, prefixB string) *Bridge {
+	return &Bridge{a: a, prefixA: prefixA, b: b, prefixB: prefixB}
+}
+
+// Sync absorbs each side's bridged entries, including deletion markers, into
+// the other side under the other side's prefix.
+func (bridge *Bridge) Sync() {
+	fromA := bridgeEntries(bridge.a, bridge.prefixA, bridge.prefixB)
+	fromB := bridgeEntries(bridge.b, bridge.prefixB, bridge.prefixA)
+	bridge.b.Absorb(fromA)
+	bridge.a.Absorb(fromB)
+}
+
+// bridgeEntries returns copies of the entries in store with keys beginning
+// with fromPrefix, with that prefix replaced by toPrefix.
+func bridgeEntries(store Storer, fromPrefix string, toPrefix string) Store {
+	entries := Store{}
+	for key, valueTimestamp := range store.ModifiedSince(MinTimestamp) {
+		if strings.HasPrefix(key, fromPrefix) {
+			entries[toPrefix+key[len(fromPrefix):]] = valueTimestamp
+		}
+	}
+	return entries
+}
+
+// Start calls Sync every interval in the background until Stop is called; an
+// interval of 0 or less is treated as one second. If the bridge was already
+// started, Start does nothing.
+func (bridge *Bridge) Start(interval time.Duration) {
+	bridge.lock.Lock()
+	if bridge.runner == nil {
+		bridge.runner = startBackground(interval, bridge.Sync)
+	}
+	bridge.lock.Unlock()
+}
+
+// Stop stops the background syncing begun by Start, waiting for any Sync in
+// progress to finish.
+func (bridge *Bridge) Stop() {
+	bridge.lock.Lock()
+	runner := bridge.runner
+	bridge.runner = nil
+	bridge.lock.Unlock()
+	if runner != nil {
+		runner.Stop()
+	}
+}
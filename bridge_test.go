@@ -0,0 +1,22 @@
+package kvt_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func TestBridgeStartStop(t *testing.T) {
+	a := kvt.NewSyncStore(nil)
+	b := kvt.NewShardedStore(4)
+	bridge := kvt.NewBridge(a, "x/", b, "y/")
+	bridge.Start(time.Millisecond)
+	bridge.Start(time.Millisecond)
+	a.Set("x/1", "one")
+	for b.Get("y/1") != "one" {
+		time.Sleep(time.Millisecond)
+	}
+	bridge.Stop()
+	bridge.Stop()
+}
@@ -0,0 +1,30 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleBridge() {
+	clusterA := kvt.NewSyncStore(nil)
+	clusterA.SetTimestamped("clusterA/region", "east", 1)
+	clusterA.SetTimestamped("private/secret", "hidden", 1)
+	hub := kvt.NewSyncStore(nil)
+	hub.SetTimestamped("shared/owner", "ops", 2)
+	hub.DeleteTimestamped("shared/region", 0)
+
+	bridge := kvt.NewBridge(clusterA, "clusterA/", hub, "shared/")
+	bridge.Sync()
+	fmt.Println("clusterA:", clusterA.SimpleString())
+	fmt.Println("hub:", hub.SimpleString())
+
+	hub.SetTimestamped("shared/region", "west", 3)
+	bridge.Sync()
+	fmt.Println("clusterA:", clusterA.SimpleString())
+
+	// Output:
+	// clusterA: clusterA/owner=ops,clusterA/region=east,private/secret=hidden
+	// hub: shared/owner=ops,shared/region=east
+	// clusterA: clusterA/owner=ops,clusterA/region=west,private/secret=hidden
+}
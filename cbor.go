@@ -0,0 +1,226 @@
+package kvt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// CBOR major types used by the encoding.
+const (
+	cborUint   = 0
+	cborNegint = 1
+	cborBytes  = 2
+	cborText   = 3
+	cborArray  = 4
+	cborMap    = 5
+	// cborNull is the whole initial byte of a null.
+	cborNull = 0xf6
+)
+
+// errCBORTruncated is returned by UnmarshalCBOR when the data ends early.
+var errCBORTruncated = errors.New("kvt: truncated CBOR store")
+
+// MarshalCBOR returns the store encoded as CBOR (RFC 8949), for constrained
+// devices syncing small stores over CoAP or MQTT. The shape mirrors the JSON
+// encoding: a map of each key, in key order, to an array of [value,
+// timestamp], or [value, timestamp, flags] if any Flags are set, with null
+// for the value of deletion markers. Lengths and integers use their shortest
+// encoding, as the deterministic encoding of RFC 8949 requires.
+//
+// The method names match the Marshaler and Unmarshaler interfaces of the
+// github.com/fxamacker/cbor package, so a Store within a larger document
+// encoded with it uses this encoding.
+func (store Store) MarshalCBOR() ([]byte, error) {
+	b := appendCBORHead(nil, cborMap, uint64(len(store)))
+	for _, key := range store.sortedKeys() {
+		valueTimestamp := store[key]
+		b = appendCBORHead(b, cborText, uint64(len(key)))
+		b = append(b, key...)
+		if valueTimestamp.Flags != 0 {
+			b = appendCBORHead(b, cborArray, 3)
+		} else {
+			b = appendCBORHead(b, cborArray, 2)
+		}
+		if valueTimestamp.Value == nil {
+			b = append(b, cborNull)
+		} else {
+			b = appendCBORHead(b, cborText, uint64(len(*valueTimestamp.Value)))
+			b = append(b, *valueTimestamp.Value...)
+		}
+		if valueTimestamp.Timestamp < 0 {
+			b = appendCBORHead(b, cborNegint, uint64(^valueTimestamp.Timestamp))
+		} else {
+			b = appendCBORHead(b, cborUint, uint64(valueTimestamp.Timestamp))
+		}
+		if valueTimestamp.Flags != 0 {
+			b = appendCBORHead(b, cborUint, uint64(valueTimestamp.Flags))
+		}
+	}
+	return b, nil
+}
+
+// appendCBORHead appends the initial byte, and any following argument bytes,
+// of a data item of the major type with the argument n.
+func appendCBORHead(b []byte, major byte, n uint64) []byte {
+	major <<= 5
+	switch {
+	case n < 24:
+		return append(b, major|byte(n))
+	case n <= math.MaxUint8:
+		return append(b, major|24, byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, major|25), uint16(n))
+	case n <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, major|26), uint32(n))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, major|27), n)
+	}
+}
+
+// UnmarshalCBOR replaces the store with the one encoded in b by MarshalCBOR.
+// Any integer or length encoding is accepted, as are byte strings rather than
+// text strings, as other CBOR libraries may choose differently; indefinite
+// lengths are not supported.
+func (store *Store) UnmarshalCBOR(b []byte) error {
+	reader := &cborReader{b: b}
+	count, err := reader.head(cborMap)
+	if err != nil {
+		return fmt.Errorf("kvt: expected CBOR map: %s", err)
+	}
+	if count > uint64(len(reader.b)) {
+		return errCBORTruncated
+	}
+	store2 := make(Store, count)
+	for i := uint64(0); i < count; i++ {
+		key, err := reader.str()
+		if err != nil {
+			return fmt.Errorf("kvt: invalid CBOR key: %s", err)
+		}
+		valueTimestamp, err := reader.valueTimestamp()
+		if err != nil {
+			return fmt.Errorf("kvt: invalid CBOR entry for %q: %s", key, err)
+		}
+		store2[key] = valueTimestamp
+	}
+	if len(reader.b) != 0 {
+		return fmt.Errorf("kvt: %d extra bytes after CBOR store", len(reader.b))
+	}
+	*store = store2
+	return nil
+}
+
+// cborReader decodes the parts of a CBOR store in turn.
+type cborReader struct {
+	b []byte
+}
+
+// next returns the next n bytes.
+func (reader *cborReader) next(n uint64) ([]byte, error) {
+	if n > uint64(len(reader.b)) {
+		return nil, errCBORTruncated
+	}
+	b := reader.b[:n]
+	reader.b = reader.b[n:]
+	return b, nil
+}
+
+// headOf returns the argument of the next data item, which must be of one of
+// the major types given, and its major type.
+func (reader *cborReader) headOf(majors ...byte) (uint64, byte, error) {
+	b, err := reader.next(1)
+	if err != nil {
+		return 0, 0, err
+	}
+	major, ai := b[0]>>5, b[0]&0x1f
+	found := false
+	for _, m := range majors {
+		found = found || m == major
+	}
+	if !found {
+		return 0, 0, fmt.Errorf("unexpected initial byte 0x%02x", b[0])
+	}
+	if ai < 24 {
+		return uint64(ai), major, nil
+	}
+	if ai > 27 {
+		return 0, 0, fmt.Errorf("unsupported initial byte 0x%02x", b[0])
+	}
+	if b, err = reader.next(1 << (ai - 24)); err != nil {
+		return 0, 0, err
+	}
+	var n uint64
+	for _, c := range b {
+		n = n<<8 | uint64(c)
+	}
+	return n, major, nil
+}
+
+// head returns the argument of the next data item, which must be of the
+// major type given.
+func (reader *cborReader) head(major byte) (uint64, error) {
+	n, _, err := reader.headOf(major)
+	return n, err
+}
+
+// str returns the next text or byte string.
+func (reader *cborReader) str() (string, error) {
+	n, _, err := reader.headOf(cborText, cborBytes)
+	if err != nil {
+		return "", err
+	}
+	b, err := reader.next(n)
+	return string(b), err
+}
+
+// int returns the next integer.
+func (reader *cborReader) int() (int64, error) {
+	n, major, err := reader.headOf(cborUint, cborNegint)
+	if err != nil {
+		return 0, err
+	}
+	if n > math.MaxInt64 {
+		return 0, errors.New("integer out of range")
+	}
+	if major == cborNegint {
+		return ^int64(n), nil
+	}
+	return int64(n), nil
+}
+
+// valueTimestamp returns the next [value, timestamp] or [value, timestamp,
+// flags] array.
+func (reader *cborReader) valueTimestamp() (*ValueTimestamp, error) {
+	n, err := reader.head(cborArray)
+	if err != nil {
+		return nil, err
+	}
+	if n != 2 && n != 3 {
+		return nil, errors.New("expected [value, timestamp] or [value, timestamp, flags]")
+	}
+	valueTimestamp := &ValueTimestamp{}
+	if len(reader.b) > 0 && reader.b[0] == cborNull {
+		reader.b = reader.b[1:]
+	} else {
+		value, err := reader.str()
+		if err != nil {
+			return nil, err
+		}
+		valueTimestamp.Value = &value
+	}
+	if valueTimestamp.Timestamp, err = reader.int(); err != nil {
+		return nil, err
+	}
+	if n == 3 {
+		flags, err := reader.int()
+		if err != nil {
+			return nil, err
+		}
+		if flags < 0 || flags > math.MaxUint32 {
+			return nil, errors.New("flags out of range")
+		}
+		valueTimestamp.Flags = Flags(flags)
+	}
+	return valueTimestamp, nil
+}
@@ -0,0 +1,68 @@
+package kvt_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestCBORRoundTrip(t *testing.T) {
+	store := kvt.Store{}
+	for i, timestamp := range []int64{0, 1, 23, 24, 255, 256, 65535, 65536, math.MaxUint32, math.MaxUint32 + 1, math.MaxInt64, -1, -24, -25, -256, -257, -65537, math.MinInt64} {
+		store.SetTimestamped(strings.Repeat("k", i*5), strings.Repeat("v", i*i*i), timestamp)
+	}
+	store.SetTimestamped("long", strings.Repeat("v", 70000), 1)
+	store.DeleteTimestamped("deleted", 2)
+	store["deleted"].Flags = kvt.FlagPinned
+	store["long"].Flags = kvt.Flags(math.MaxUint32)
+	for i := 0; i < 30; i++ {
+		store.SetTimestamped(string(rune('A'+i)), "", int64(i))
+	}
+	b, err := store.MarshalCBOR()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded kvt.Store
+	if err = loaded.UnmarshalCBOR(b); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := loaded.String(), store.String(); a != b {
+		t.Fatal(a, b)
+	}
+	for i := 0; i < len(b); i += 97 {
+		if err = loaded.UnmarshalCBOR(b[:i]); err == nil {
+			t.Fatal("no error truncated to", i, "of", len(b))
+		}
+	}
+}
+
+func TestUnmarshalCBOROtherEncodings(t *testing.T) {
+	// {"A": [h'6f6e65', 1 as uint64], "B": [null, -2, 4 as uint16]}
+	b := []byte{0xb8, 2,
+		0x61, 'A', 0x98, 2, 0x43, 'o', 'n', 'e', 0x1b, 0, 0, 0, 0, 0, 0, 0, 1,
+		0x78, 1, 'B', 0x83, 0xf6, 0x21, 0x19, 0, 4,
+	}
+	var store kvt.Store
+	if err := store.UnmarshalCBOR(b); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := store.String(), `{"A":["one",1],"B":[null,-2,4]}`; a != b {
+		t.Fatal(a, b)
+	}
+	for _, junk := range [][]byte{
+		{},
+		{0x80},
+		{0xbf, 0xff},
+		append(append([]byte{}, b...), 0),
+		{0xa1, 0x61, 'A', 0x81, 0xf6},
+		{0xa1, 0x61, 'A', 0x82, 0x01, 0x01},
+		{0xa1, 0x61, 'A', 0x82, 0xf6, 0x1b, 0x80, 0, 0, 0, 0, 0, 0, 0},
+		{0xa1, 0x61, 'A', 0x83, 0xf6, 0x01, 0x20},
+	} {
+		if err := store.UnmarshalCBOR(junk); err == nil {
+			t.Errorf("expected error from %x", junk)
+		}
+	}
+}
@@ -0,0 +1,24 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_MarshalCBOR() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	b, err := store.MarshalCBOR()
+	fmt.Printf("% x %v\n", b, err)
+
+	var store2 kvt.Store
+	fmt.Println(store2.UnmarshalCBOR(b))
+	fmt.Println(store2)
+
+	// Output:
+	// a2 61 41 82 63 6f 6e 65 01 61 42 82 f6 02 <nil>
+	// <nil>
+	// {"A":["one",1],"B":[null,2]}
+}
@@ -0,0 +1,73 @@
+package kvt
+
+import "strings"
+
+// Census summarizes the shape of a store without including any values and
+// with only the leading segments of keys, so it is suitable to hand to
+// external telemetry for capacity planning.
+type Census struct {
+	// Live is the number of entries with values.
+	Live int
+	// Deleted is the number of deletion markers.
+	Deleted int
+	// Prefixes counts entries, including deletion markers, by their leading
+	// key segments.
+	Prefixes map[string]int
+	// OtherPrefixes counts entries whose prefixes were too rare to report
+	// individually in Prefixes.
+	OtherPrefixes int
+	// KeySizes counts keys by their length in bytes, rounded up to the next
+	// power of two.
+	KeySizes map[int]int
+	// ValueSizes counts values by their length in bytes, rounded up to the
+	// next power of two; deletion markers are not counted.
+	ValueSizes map[int]int
+}
+
+// Census returns a Census of the store. Prefixes are the first depth segments
+// of each key as split by separator, with a depth less than 0 treated as 0;
+// any prefix shared by fewer than minCount entries is counted in
+// OtherPrefixes instead, so that rare keys can't be singled out.
+func (store Store) Census(separator string, depth int, minCount int) *Census {
+	if depth < 0 {
+		depth = 0
+	}
+	census := &Census{
+		Prefixes:   map[string]int{},
+		KeySizes:   map[int]int{},
+		ValueSizes: map[int]int{},
+	}
+	for key, valueTimestamp := range store {
+		if valueTimestamp.Value == nil {
+			census.Deleted++
+		} else {
+			census.Live++
+			census.ValueSizes[sizeBucket(len(*valueTimestamp.Value))]++
+		}
+		census.KeySizes[sizeBucket(len(key))]++
+		segments := strings.SplitN(key, separator, depth+1)
+		if len(segments) > depth {
+			segments = segments[:depth]
+		}
+		census.Prefixes[strings.Join(segments, separator)]++
+	}
+	for prefix, count := range census.Prefixes {
+		if count < minCount {
+			delete(census.Prefixes, prefix)
+			census.OtherPrefixes += count
+		}
+	}
+	return census
+}
+
+// sizeBucket returns size rounded up to the next power of two.
+func sizeBucket(size int) int {
+	if size == 0 {
+		return 0
+	}
+	bucket := 1
+	for bucket < size {
+		bucket <<= 1
+	}
+	return bucket
+}
@@ -0,0 +1,25 @@
+package kvt_test
+
+import (
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestCensusDepthAndOther(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("*", "star", 1)
+	store.SetTimestamped("a/b", "one", 1)
+	census := store.Census("/", -1, 1)
+	if len(census.Prefixes) != 1 || census.Prefixes[""] != 2 || census.OtherPrefixes != 0 {
+		t.Fatal(census.Prefixes, census.OtherPrefixes)
+	}
+	census = store.Census("/", 1, 2)
+	if len(census.Prefixes) != 0 || census.OtherPrefixes != 2 {
+		t.Fatal(census.Prefixes, census.OtherPrefixes)
+	}
+	census = store.Census("/", 1, 1)
+	if census.Prefixes["*"] != 1 || census.Prefixes["a"] != 1 || census.OtherPrefixes != 0 {
+		t.Fatal(census.Prefixes, census.OtherPrefixes)
+	}
+}
@@ -0,0 +1,23 @@
+package kvt_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_Census() {
+	store := kvt.Store{}
+	store.Set("net/iface0/mtu", "1500")
+	store.Set("net/iface0/addr", "10.0.0.1")
+	store.Set("net/iface1/mtu", "9000")
+	store.Delete("net/iface1/addr")
+	store.Set("secret/api-token", "do-not-share")
+	census := store.Census("/", 2, 2)
+	b, _ := json.Marshal(census)
+	fmt.Println(string(b))
+
+	// Output:
+	// {"Live":4,"Deleted":1,"Prefixes":{"net/iface0":2,"net/iface1":2},"OtherPrefixes":1,"KeySizes":{"16":5},"ValueSizes":{"16":1,"4":2,"8":1}}
+}
@@ -0,0 +1,177 @@
+package kvt
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ChangeJournal appends every change to a SyncStore to a file as a line of
+// text, for audit trails and for replaying the changes into other systems.
+// See SyncStore.OpenChangeJournal.
+//
+// Each line has the form: timestamp "key" op "value"
+//
+// The key and value are quoted as by strconv.Quote. The op is "set" or
+// "delete" for local writes, or "absorb-set" or "absorb-delete" for changes
+// from an Absorb; deletions have no value. If the entry has any Flags, the
+// timestamp is followed by a comma and the flags as a decimal number, as in
+// 123,4 for FlagPinned.
+type ChangeJournal struct {
+	lock   sync.Mutex
+	f      *os.File
+	closed bool
+	err    error
+	// stopListening unregisters changed from the SyncStore.
+	stopListening func()
+}
+
+// OpenChangeJournal opens the file at path for appending, creating it if
+// needed, and appends to it every subsequent change to syncStore. Each write's
+// changes are appended before the write returns, but the file is not synced.
+func (syncStore *SyncStore) OpenChangeJournal(path string) (*ChangeJournal, error) {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	changeJournal := &ChangeJournal{f: f}
+	changeJournal.stopListening = syncStore.listen(&listener{changed: changeJournal.changed})
+	return changeJournal, nil
+}
+
+// changed is the SyncStore hook for each batch of changes.
+func (changeJournal *ChangeJournal) changed(changes []Change) {
+	var b bytes.Buffer
+	for _, change := range changes {
+		op := "set"
+		if change.New.Value == nil {
+			op = "delete"
+		}
+		if change.Absorbed {
+			op = "absorb-" + op
+		}
+		b.WriteString(strconv.FormatInt(change.New.Timestamp, 10))
+		if change.New.Flags != 0 {
+			b.WriteString("," + strconv.FormatUint(uint64(change.New.Flags), 10))
+		}
+		fmt.Fprintf(&b, " %s %s", strconv.Quote(change.Key), op)
+		if change.New.Value != nil {
+			b.WriteString(" " + strconv.Quote(*change.New.Value))
+		}
+		b.WriteByte('\n')
+	}
+	changeJournal.lock.Lock()
+	defer changeJournal.lock.Unlock()
+	if changeJournal.closed || changeJournal.err != nil {
+		return
+	}
+	_, changeJournal.err = changeJournal.f.Write(b.Bytes())
+}
+
+// Err returns the first error encountered while appending to the file, if
+// any. After an error, nothing more is appended.
+func (changeJournal *ChangeJournal) Err() error {
+	changeJournal.lock.Lock()
+	defer changeJournal.lock.Unlock()
+	return changeJournal.err
+}
+
+// Close stops appending and closes the file, returning the first error
+// encountered, if any.
+func (changeJournal *ChangeJournal) Close() error {
+	changeJournal.stopListening()
+	changeJournal.lock.Lock()
+	defer changeJournal.lock.Unlock()
+	if changeJournal.closed {
+		return changeJournal.err
+	}
+	changeJournal.closed = true
+	if err := changeJournal.f.Close(); changeJournal.err == nil {
+		changeJournal.err = err
+	}
+	return changeJournal.err
+}
+
+// ReadChangeJournal returns a store with the changes recorded in the change
+// journal read from r, as written by a ChangeJournal, absorbed in turn.
+func ReadChangeJournal(r io.Reader) (Store, error) {
+	store := Store{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	for line := 1; scanner.Scan(); line++ {
+		valueTimestamp, key, err := parseChangeJournalLine(scanner.Text())
+		if err != nil {
+			return nil, fmt.Errorf("%s on change journal line %d", err, line)
+		}
+		store.absorbEntry(key, valueTimestamp)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// LoadChangeJournalFile is like ReadChangeJournal but reads the change
+// journal file at path.
+func LoadChangeJournalFile(path string) (Store, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadChangeJournal(f)
+}
+
+// parseChangeJournalLine returns the entry and key recorded by a change
+// journal line.
+func parseChangeJournalLine(s string) (*ValueTimestamp, string, error) {
+	parts := strings.SplitN(s, " ", 2)
+	if len(parts) != 2 {
+		return nil, "", errors.New("invalid line")
+	}
+	timestampField, flagsField := parts[0], ""
+	if i := strings.IndexByte(timestampField, ','); i >= 0 {
+		timestampField, flagsField = timestampField[:i], timestampField[i+1:]
+	}
+	timestamp, err := strconv.ParseInt(timestampField, 10, 64)
+	if err != nil {
+		return nil, "", errors.New("invalid timestamp")
+	}
+	var flags uint64
+	if timestampField != parts[0] {
+		if flags, err = strconv.ParseUint(flagsField, 10, 32); err != nil {
+			return nil, "", errors.New("invalid flags")
+		}
+	}
+	quoted, err := strconv.QuotedPrefix(parts[1])
+	if err != nil {
+		return nil, "", errors.New("invalid key")
+	}
+	key, _ := strconv.Unquote(quoted)
+	parts = strings.SplitN(strings.TrimPrefix(parts[1][len(quoted):], " "), " ", 2)
+	valueTimestamp := &ValueTimestamp{Timestamp: timestamp, Flags: Flags(flags)}
+	switch strings.TrimPrefix(parts[0], "absorb-") {
+	case "set":
+		if len(parts) != 2 {
+			return nil, "", errors.New("missing value")
+		}
+		value, err := strconv.Unquote(parts[1])
+		if err != nil {
+			return nil, "", errors.New("invalid value")
+		}
+		valueTimestamp.Value = &value
+	case "delete":
+		if len(parts) != 1 {
+			return nil, "", errors.New("unexpected value")
+		}
+	default:
+		return nil, "", fmt.Errorf("invalid op %q", parts[0])
+	}
+	return valueTimestamp, key, nil
+}
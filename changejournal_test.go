@@ -0,0 +1,45 @@
+package kvt_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestReadChangeJournalJunk(t *testing.T) {
+	for _, junk := range []string{"1\n", "x \"A\" set \"v\"\n", "1 A set \"v\"\n", "1 \"A\" set\n", "1 \"A\" set v\n", "1 \"A\" delete \"v\"\n", "1 \"A\" bogus\n", "1, \"A\" delete\n", "1,x \"A\" delete\n", "1,4294967296 \"A\" delete\n"} {
+		if _, err := kvt.ReadChangeJournal(strings.NewReader(junk)); err == nil {
+			t.Error(junk)
+		}
+	}
+}
+
+func TestChangeJournalFlags(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "changes")
+	syncStore := kvt.NewSyncStore(nil)
+	changeJournal, err := syncStore.OpenChangeJournal(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store2 := kvt.Store{"A": {Timestamp: 1, Flags: kvt.FlagPinned}, "B": {Timestamp: 2}}
+	store2.SetTimestamped("C", "three", 3)
+	store2["C"].Flags = kvt.FlagEncrypted
+	syncStore.Absorb(store2)
+	if err = changeJournal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := string(b); s != "1,4 \"A\" absorb-delete\n2 \"B\" absorb-delete\n3,1 \"C\" absorb-set \"three\"\n" {
+		t.Fatal(s)
+	}
+	replayed, err := kvt.LoadChangeJournalFile(path)
+	if err != nil || replayed.String() != syncStore.String() {
+		t.Fatal(replayed, err)
+	}
+}
@@ -0,0 +1,42 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore_OpenChangeJournal() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "changes.log")
+
+	syncStore := kvt.NewSyncStore(nil)
+	changeJournal, err := syncStore.OpenChangeJournal(path)
+	if err != nil {
+		panic(err)
+	}
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.SetTimestamped("B c", "two\nlines", 2)
+	syncStore.DeleteTimestamped("A", 3)
+	syncStore.Absorb(kvt.Store{"D": {Timestamp: 4}})
+	fmt.Println(changeJournal.Close())
+	b, _ := os.ReadFile(path)
+	fmt.Print(string(b))
+
+	// The journal can be replayed into another store.
+	fmt.Println(kvt.LoadChangeJournalFile(path))
+
+	// Output:
+	// <nil>
+	// 1 "A" set "one"
+	// 2 "B c" set "two\nlines"
+	// 3 "A" delete
+	// 4 "D" absorb-delete
+	// {"A":[null,3],"B c":["two\nlines",2],"D":[null,4]} <nil>
+}
@@ -0,0 +1,127 @@
+package kvt
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// checksummedHeader begins every snapshot written by WriteChecksummed; the
+// number is the format version.
+const checksummedHeader = "kvt-checksummed 1\n"
+
+// CorruptError is returned when a checksummed snapshot is truncated or its
+// contents don't match its checksum.
+type CorruptError struct {
+	// Reason describes what was wrong.
+	Reason string
+}
+
+func (err *CorruptError) Error() string {
+	return "kvt: corrupt snapshot: " + err.Reason
+}
+
+// WriteChecksummed writes the store to w as a checksummed snapshot: a header
+// line with the format version, a line with the SHA-256 and length of the
+// JSON encoded store, and then the JSON itself. ReadChecksummed, and
+// LoadFile, use these to detect truncation and corruption.
+func (store Store) WriteChecksummed(w io.Writer) error {
+	b, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	sum := sha256.Sum256(b)
+	if _, err = fmt.Fprintf(w, "%ssha256 %s %d\n", checksummedHeader, hex.EncodeToString(sum[:]), len(b)); err != nil {
+		return err
+	}
+	_, err = w.Write(b)
+	return err
+}
+
+// SaveFileChecksummed is like SaveFile but writes a checksummed snapshot, as
+// WriteChecksummed does.
+func (store Store) SaveFileChecksummed(path string) error {
+	var buf bytes.Buffer
+	if err := store.WriteChecksummed(&buf); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// ReadChecksummed reads a checksummed snapshot written by WriteChecksummed,
+// returning a *CorruptError if it is truncated or doesn't match its checksum.
+func ReadChecksummed(r io.Reader) (Store, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+	return readChecksummed(b)
+}
+
+// readChecksummed decodes a checksummed snapshot held in b.
+func readChecksummed(b []byte) (Store, error) {
+	if !bytes.HasPrefix(b, []byte(checksummedHeader)) {
+		if version, ok := checksummedVersion(b); ok {
+			return nil, fmt.Errorf("kvt: unsupported checksummed snapshot version %s", version)
+		}
+		return nil, &CorruptError{Reason: "missing header"}
+	}
+	b = b[len(checksummedHeader):]
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return nil, &CorruptError{Reason: "missing checksum"}
+	}
+	fields := strings.Fields(string(b[:i]))
+	b = b[i+1:]
+	if len(fields) != 3 || fields[0] != "sha256" {
+		return nil, &CorruptError{Reason: "invalid checksum line"}
+	}
+	length, err := strconv.Atoi(fields[2])
+	if err != nil || length < 0 {
+		return nil, &CorruptError{Reason: "invalid length"}
+	}
+	if len(b) < length {
+		return nil, &CorruptError{Reason: fmt.Sprintf("truncated to %d of %d bytes", len(b), length)}
+	}
+	if len(b) > length {
+		return nil, &CorruptError{Reason: fmt.Sprintf("%d unexpected trailing bytes", len(b)-length)}
+	}
+	sum := sha256.Sum256(b)
+	if hex.EncodeToString(sum[:]) != fields[1] {
+		return nil, &CorruptError{Reason: "checksum mismatch"}
+	}
+	store := Store{}
+	if err = json.Unmarshal(b, &store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// isChecksummed returns true if b appears to be a checksummed snapshot,
+// including one truncated partway through its header line.
+func isChecksummed(b []byte) bool {
+	if _, ok := checksummedVersion(b); ok {
+		return true
+	}
+	return len(b) > 0 && len(b) < len(checksummedHeader) && strings.HasPrefix(checksummedHeader, string(b))
+}
+
+// checksummedVersion returns the version from b's header if b begins with a
+// checksummed snapshot header of any version.
+func checksummedVersion(b []byte) (string, bool) {
+	prefix := checksummedHeader[:strings.IndexByte(checksummedHeader, ' ')+1]
+	if !bytes.HasPrefix(b, []byte(prefix)) {
+		return "", false
+	}
+	b = b[len(prefix):]
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return "", false
+	}
+	return string(b[:i]), true
+}
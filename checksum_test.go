@@ -0,0 +1,60 @@
+package kvt_test
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestLoadFileChecksummedCorruption(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.kvt")
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	if err := store.SaveFileChecksummed(path); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := kvt.LoadFile(path)
+	if err != nil || loaded.String() != store.String() {
+		t.Fatal(loaded, err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	flipped := append([]byte{}, b...)
+	flipped[len(flipped)-3] ^= 1
+	for name, damaged := range map[string][]byte{
+		"truncated": b[:len(b)-4],
+		"flipped":   flipped,
+		"appended":  append(append([]byte{}, b...), "junk"...),
+		"no sum":    b[:bytes.IndexByte(b, '\n')+1],
+	} {
+		if err = os.WriteFile(path, damaged, 0666); err != nil {
+			t.Fatal(err)
+		}
+		var corruptError *kvt.CorruptError
+		if _, err = kvt.LoadFile(path); !errors.As(err, &corruptError) {
+			t.Errorf("%s: expected *CorruptError, got %v", name, err)
+		}
+	}
+	// Truncated within the header line.
+	for i := 1; i <= bytes.IndexByte(b, '\n'); i++ {
+		if err = os.WriteFile(path, b[:i], 0666); err != nil {
+			t.Fatal(err)
+		}
+		var corruptError *kvt.CorruptError
+		if _, err = kvt.LoadFile(path); !errors.As(err, &corruptError) {
+			t.Errorf("truncated to %d bytes: expected *CorruptError, got %v", i, err)
+		}
+	}
+	newer := strings.Replace(string(b), "kvt-checksummed 1", "kvt-checksummed 2", 1)
+	if _, err = kvt.ReadChecksummed(strings.NewReader(newer)); err == nil || !strings.Contains(err.Error(), "version 2") {
+		t.Error(err)
+	}
+}
@@ -0,0 +1,28 @@
+package kvt_test
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_WriteChecksummed() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	var buf bytes.Buffer
+	store.WriteChecksummed(&buf)
+	fmt.Print(buf.String())
+	fmt.Println()
+
+	fmt.Println(kvt.ReadChecksummed(bytes.NewReader(buf.Bytes())))
+	_, err := kvt.ReadChecksummed(bytes.NewReader(buf.Bytes()[:buf.Len()-1]))
+	fmt.Println(err)
+
+	// Output:
+	// kvt-checksummed 1
+	// sha256 e100fd2265ae752a6a4d0d57de284f71bae4718bb492beeff9a05d77c50b3fbe 15
+	// {"A":["one",1]}
+	// {"A":["one",1]} <nil>
+	// kvt: corrupt snapshot: truncated to 14 of 15 bytes
+}
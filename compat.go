@@ -0,0 +1,49 @@
+package kvt
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// Snapshot format versions understood by WriteCompat.
+const (
+	// FormatV1 encodes every entry as [value,timestamp].
+	FormatV1 = 1
+	// FormatV2 adds the optional third flags element, [value,timestamp,flags].
+	FormatV2 = 2
+	// FormatCurrent is the version written by MarshalJSON.
+	FormatCurrent = FormatV2
+)
+
+// WriteCompat writes the JSON encoded store to w in the given snapshot format
+// version, so that older readers can load it. Anything the older format can't
+// hold is dropped, and the keys whose entries lost information are returned in
+// sorted order so the caller can decide whether that's acceptable.
+func (store Store) WriteCompat(w io.Writer, version int) ([]string, error) {
+	var lost []string
+	out := store
+	switch version {
+	case FormatV1:
+		out = make(Store, len(store))
+		for _, key := range store.sortedKeys() {
+			valueTimestamp := store[key]
+			if valueTimestamp != nil && valueTimestamp.Flags != 0 {
+				valueTimestamp = &ValueTimestamp{valueTimestamp.Value, valueTimestamp.Timestamp, 0}
+				lost = append(lost, key)
+			}
+			out[key] = valueTimestamp
+		}
+	case FormatV2:
+	default:
+		return nil, fmt.Errorf("unknown snapshot format version %d", version)
+	}
+	b, err := json.Marshal(out)
+	if err != nil {
+		return nil, err
+	}
+	if _, err = w.Write(b); err != nil {
+		return nil, err
+	}
+	return lost, nil
+}
@@ -0,0 +1,27 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_WriteCompat() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.SetTimestamped("B", "two", 2)
+	store["B"].Flags = kvt.FlagPinned
+	lost, err := store.WriteCompat(os.Stdout, kvt.FormatV1)
+	fmt.Println()
+	fmt.Println(lost, err)
+	lost, err = store.WriteCompat(os.Stdout, kvt.FormatV2)
+	fmt.Println()
+	fmt.Println(lost, err)
+
+	// Output:
+	// {"A":["one",1],"B":["two",2]}
+	// [B] <nil>
+	// {"A":["one",1],"B":["two",2,4]}
+	// [] <nil>
+}
@@ -0,0 +1,93 @@
+package kvt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// deltaHeader is the first line of every delta written by WriteDelta; the
+// number is the format version.
+const deltaHeader = "kvt-delta 1\n"
+
+// WriteDelta writes a delta document to w holding just the entries, including
+// deletion markers, that base lacks or holds an older version of, so a replica
+// known to have everything in base, such as a copy of the store kept from the
+// last time the replica caught up, can catch up without a full snapshot. The
+// document is a header line, "kvt-delta 1", followed by the entries as Export
+// lines.
+//
+// Entries are compared with base rather than filtered by timestamp, since
+// timestamps are write times from whichever replica made the write; an entry
+// absorbed from an offline replica may be older than base's newest entry but
+// still missing from it.
+//
+// Deletion markers purged from the store but still in base are not included;
+// see PurgeToLedger for keeping track of those.
+func (store Store) WriteDelta(w io.Writer, base Store) error {
+	if _, err := io.WriteString(w, deltaHeader); err != nil {
+		return err
+	}
+	return store.newerThan(base).Export(w)
+}
+
+// newerThan returns a new store holding copies of the entries that base lacks
+// or has an older timestamp for; that is, those that base would take if it
+// absorbed the store.
+func (store Store) newerThan(base Store) Store {
+	store2 := Store{}
+	for key, valueTimestamp := range store {
+		if baseValueTimestamp := base[key]; baseValueTimestamp == nil || baseValueTimestamp.Timestamp < valueTimestamp.Timestamp {
+			valueTimestampCopy := *valueTimestamp
+			store2[key] = &valueTimestampCopy
+		}
+	}
+	return store2
+}
+
+// ApplyDeltaFrom reads a delta document written by WriteDelta from r,
+// absorbing each entry into the store as it is read, and returns how many
+// entries were read. On error, the entries read before the problem will
+// already have been absorbed.
+func (store Store) ApplyDeltaFrom(r io.Reader) (int, error) {
+	reader := bufio.NewReader(r)
+	if err := readDeltaHeader(reader); err != nil {
+		return 0, err
+	}
+	return store.Import(reader)
+}
+
+// readDeltaHeader reads and checks the header line of a delta document.
+func readDeltaHeader(reader *bufio.Reader) error {
+	line, err := reader.ReadString('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	if line != deltaHeader {
+		if strings.HasPrefix(line, "kvt-delta ") {
+			return fmt.Errorf("kvt: unsupported delta header %q", strings.TrimSpace(line))
+		}
+		return fmt.Errorf("kvt: not a delta document")
+	}
+	return nil
+}
+
+// WriteDelta writes a delta document of the entries base lacks or holds an
+// older version of to w; see Store.WriteDelta. The lock is only held while
+// finding the entries.
+func (syncStore *SyncStore) WriteDelta(w io.Writer, base Store) error {
+	var delta Store
+	syncStore.read(func(store Store) { delta = store.newerThan(base) })
+	return delta.WriteDelta(w, nil)
+}
+
+// ApplyDeltaFrom absorbs the entries of the delta document read from r; see
+// Store.ApplyDeltaFrom. Entries are absorbed in batches, as by Import.
+func (syncStore *SyncStore) ApplyDeltaFrom(r io.Reader) (int, error) {
+	reader := bufio.NewReader(r)
+	if err := readDeltaHeader(reader); err != nil {
+		return 0, err
+	}
+	return syncStore.Import(reader)
+}
@@ -0,0 +1,47 @@
+package kvt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestApplyDeltaFromJunk(t *testing.T) {
+	for _, junk := range []string{
+		"",
+		`["A","one",1]` + "\n",
+		"kvt-delta 1",
+		"kvt-delta 1 2\n",
+		"kvt-delta 2\n",
+		"kvt-delta 1\nnot json\n",
+	} {
+		if _, err := (kvt.Store{}).ApplyDeltaFrom(strings.NewReader(junk)); err == nil {
+			t.Errorf("expected error from %q", junk)
+		}
+		if _, err := kvt.NewSyncStore(nil).ApplyDeltaFrom(strings.NewReader(junk)); err == nil {
+			t.Errorf("expected error from %q", junk)
+		}
+	}
+}
+
+func TestSyncStoreDelta(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.SetTimestamped("A", "one", 1)
+	base := syncStore.Copy()
+	syncStore.SetTimestamped("B", "two", 2)
+	var sb strings.Builder
+	if err := syncStore.WriteDelta(&sb, base); err != nil {
+		t.Fatal(err)
+	}
+	if s := sb.String(); s != "kvt-delta 1\n[\"B\",\"two\",2]\n" {
+		t.Fatal(s)
+	}
+	syncStore2 := kvt.NewSyncStore(nil)
+	if n, err := syncStore2.ApplyDeltaFrom(strings.NewReader(sb.String())); n != 1 || err != nil {
+		t.Fatal(n, err)
+	}
+	if s := syncStore2.String(); s != `{"B":["two",2]}` {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,44 @@
+package kvt_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_WriteDelta() {
+	primary := kvt.Store{}
+	primary.SetTimestamped("A", "one", 1)
+	primary.SetTimestamped("B", "two", 2)
+	replica := primary.Copy()
+	shipped := primary.Copy()
+
+	primary.SetTimestamped("B", "deux", 3)
+	primary.DeleteTimestamped("A", 4)
+	primary.SetTimestamped("C", "three", 5)
+
+	// An entry written earlier by a node that was offline arrives late.
+	offline := kvt.Store{}
+	offline.SetTimestamped("D", "four", 1)
+	primary.Absorb(offline)
+
+	// Only what changed since the replica caught up is shipped.
+	var buf bytes.Buffer
+	if err := primary.WriteDelta(&buf, shipped); err != nil {
+		panic(err)
+	}
+	os.Stdout.Write(buf.Bytes())
+	fmt.Println(replica.ApplyDeltaFrom(&buf))
+	fmt.Println(replica.Hash() == primary.Hash())
+
+	// Output:
+	// kvt-delta 1
+	// ["A",null,4]
+	// ["B","deux",3]
+	// ["C","three",5]
+	// ["D","four",1]
+	// 4 <nil>
+	// true
+}
@@ -0,0 +1,90 @@
+package kvt
+
+import (
+	"sort"
+	"strings"
+)
+
+// Dependencies declares which keys depend on others, such as per-node service
+// registrations depending on the node's own registration. It maps each parent
+// key to the key prefixes of its dependents.
+type Dependencies map[string][]string
+
+// CascadeDelete is equivalent to CascadeDeleteTimestamped(deps, key, Now()).
+func (store Store) CascadeDelete(deps Dependencies, key string) []string {
+	return store.CascadeDeleteTimestamped(deps, key, Now())
+}
+
+// CascadeDeleteTimestamped records deletion markers for the key and every key
+// with a value that depends on it, directly or indirectly, all using the same
+// timestamp. The sorted keys that were deleted are returned; as with
+// DeleteTimestamped, keys with newer or equal timestamps are left alone.
+func (store Store) CascadeDeleteTimestamped(deps Dependencies, key string, timestamp int64) []string {
+	var deleted []string
+	seen := map[string]bool{}
+	pending := []string{key}
+	for len(pending) > 0 {
+		parent := pending[0]
+		pending = pending[1:]
+		if seen[parent] {
+			continue
+		}
+		seen[parent] = true
+		if valueTimestamp := store[parent]; valueTimestamp != nil && valueTimestamp.Value != nil && valueTimestamp.Timestamp < timestamp {
+			store.DeleteTimestamped(parent, timestamp)
+			deleted = append(deleted, parent)
+		}
+		for _, prefix := range deps[parent] {
+			for k, valueTimestamp := range store {
+				if valueTimestamp.Value != nil && strings.HasPrefix(k, prefix) {
+					pending = append(pending, k)
+				}
+			}
+		}
+	}
+	sort.Strings(deleted)
+	return deleted
+}
+
+// Orphans returns the sorted keys with values that depend on a parent key that
+// has no value, such as would be left behind by deleting a parent without
+// CascadeDelete.
+func (store Store) Orphans(deps Dependencies) []string {
+	var orphans []string
+	seen := map[string]bool{}
+	for parent, prefixes := range deps {
+		if valueTimestamp := store[parent]; valueTimestamp != nil && valueTimestamp.Value != nil {
+			continue
+		}
+		for _, prefix := range prefixes {
+			for k, valueTimestamp := range store {
+				if valueTimestamp.Value != nil && strings.HasPrefix(k, prefix) && !seen[k] {
+					seen[k] = true
+					orphans = append(orphans, k)
+				}
+			}
+		}
+	}
+	sort.Strings(orphans)
+	return orphans
+}
+
+// CascadeDelete is equivalent to CascadeDeleteTimestamped(deps, key, Now()).
+func (syncStore *SyncStore) CascadeDelete(deps Dependencies, key string) (deleted []string) {
+	syncStore.write(func(store Store) { deleted = store.CascadeDelete(deps, key) })
+	return deleted
+}
+
+// CascadeDeleteTimestamped deletes the key and its dependents in one step;
+// see Store.CascadeDeleteTimestamped.
+func (syncStore *SyncStore) CascadeDeleteTimestamped(deps Dependencies, key string, timestamp int64) (deleted []string) {
+	syncStore.write(func(store Store) { deleted = store.CascadeDeleteTimestamped(deps, key, timestamp) })
+	return deleted
+}
+
+// Orphans returns the keys whose parent keys have no value; see
+// Store.Orphans.
+func (syncStore *SyncStore) Orphans(deps Dependencies) (orphans []string) {
+	syncStore.read(func(store Store) { orphans = store.Orphans(deps) })
+	return orphans
+}
@@ -0,0 +1,43 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_CascadeDelete() {
+	deps := kvt.Dependencies{
+		"nodes/alpha":        {"services/alpha/"},
+		"services/alpha/web": {"endpoints/alpha/web/"},
+		"nodes/beta":         {"services/beta/"},
+	}
+	store := kvt.Store{}
+	store.Set("nodes/alpha", "10.0.0.1")
+	store.Set("services/alpha/web", "80")
+	store.Set("services/alpha/db", "5432")
+	store.Set("endpoints/alpha/web/health", "/healthz")
+	store.Set("nodes/beta", "10.0.0.2")
+	store.Set("services/beta/web", "80")
+	fmt.Println(store.CascadeDelete(deps, "nodes/alpha"))
+	fmt.Println(store.PrefixKeys(""))
+
+	// Output:
+	// [endpoints/alpha/web/health nodes/alpha services/alpha/db services/alpha/web]
+	// [nodes/beta services/beta/web]
+}
+
+func ExampleStore_Orphans() {
+	deps := kvt.Dependencies{"nodes/alpha": {"services/alpha/"}}
+	store := kvt.Store{}
+	store.Set("nodes/alpha", "10.0.0.1")
+	store.Set("services/alpha/web", "80")
+	fmt.Println(store.Orphans(deps))
+	// Deleting the parent directly leaves its dependents orphaned.
+	store.Delete("nodes/alpha")
+	fmt.Println(store.Orphans(deps))
+
+	// Output:
+	// []
+	// [services/alpha/web]
+}
@@ -0,0 +1,235 @@
+package kvt
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// dirFileSuffix ends the name of every entry file written by SaveDir.
+const dirFileSuffix = ".kvt"
+
+// SaveDir saves the store to the directory at dir with one small file per
+// entry, which suits keeping configuration in version control. Each key is
+// split on "/" into directories, with unusual characters percent escaped, and
+// the last segment names the file, with a ".kvt" suffix. Uppercase letters
+// are escaped too, so that keys differing only in case don't share a file on
+// case-insensitive file systems. A file's first line
+// holds the timestamp, followed by "deleted" for deletion markers and by
+// "flags=N" when there are flags; the value follows verbatim on the next
+// line. Only files whose contents have changed are rewritten, and files for
+// keys no longer in the store are removed.
+func (store Store) SaveDir(dir string) error {
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return err
+	}
+	want := map[string]bool{}
+	for _, key := range store.sortedKeys() {
+		path := filepath.Join(dir, dirKeyPath(key))
+		want[path] = true
+		b := dirFileContents(store[key])
+		if existing, err := os.ReadFile(path); err == nil && bytes.Equal(existing, b) {
+			continue
+		}
+		if err := os.MkdirAll(filepath.Dir(path), 0777); err != nil {
+			return err
+		}
+		if err := writeFileAtomic(path, b); err != nil {
+			return err
+		}
+	}
+	var stale, dirs []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			if path != dir {
+				dirs = append(dirs, path)
+			}
+		} else if strings.HasSuffix(path, dirFileSuffix) && !want[path] {
+			stale = append(stale, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, path := range stale {
+		if err = os.Remove(path); err != nil {
+			return err
+		}
+	}
+	// Remove directories left empty, deepest first; those that aren't empty
+	// simply fail to be removed.
+	for i := len(dirs) - 1; i >= 0; i-- {
+		os.Remove(dirs[i])
+	}
+	return nil
+}
+
+// LoadDir loads the store saved to the directory at dir by SaveDir.
+func LoadDir(dir string) (Store, error) {
+	store := Store{}
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() || !strings.HasSuffix(path, dirFileSuffix) {
+			return nil
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		key, err := dirPathKey(filepath.ToSlash(rel))
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		valueTimestamp, err := parseDirFile(b)
+		if err != nil {
+			return fmt.Errorf("%s: %s", path, err)
+		}
+		store[key] = valueTimestamp
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// dirKeyPath returns the relative path of the file for key.
+func dirKeyPath(key string) string {
+	segments := strings.Split(key, "/")
+	for i, segment := range segments {
+		segments[i] = escapeDirSegment(segment)
+	}
+	return filepath.Join(segments...) + dirFileSuffix
+}
+
+// dirPathKey returns the key for the slash separated relative path of a file.
+func dirPathKey(path string) (string, error) {
+	segments := strings.Split(strings.TrimSuffix(path, dirFileSuffix), "/")
+	for i, segment := range segments {
+		unescaped, err := unescapeDirSegment(segment)
+		if err != nil {
+			return "", err
+		}
+		segments[i] = unescaped
+	}
+	return strings.Join(segments, "/"), nil
+}
+
+// escapeDirSegment percent escapes the bytes of segment that aren't safe in
+// file names, including a leading dot and, for case-insensitive file systems,
+// uppercase letters. An empty segment becomes a lone "%".
+func escapeDirSegment(segment string) string {
+	if segment == "" {
+		return "%"
+	}
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		c := segment[i]
+		if ('a' <= c && c <= 'z') || ('0' <= c && c <= '9') || c == '-' || c == '_' || (c == '.' && i > 0) {
+			b.WriteByte(c)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// unescapeDirSegment reverses escapeDirSegment. Segments that
+// escapeDirSegment wouldn't have written, such as with a needless escape like
+// "%61", an unescaped "A", or an escaped "/", which keys are split on rather
+// than escaped, are rejected, so that no two file names give the same key.
+func unescapeDirSegment(segment string) (string, error) {
+	if segment == "%" {
+		return "", nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(segment); i++ {
+		if segment[i] != '%' {
+			b.WriteByte(segment[i])
+			continue
+		}
+		if i+3 > len(segment) {
+			return "", fmt.Errorf("invalid escape in %q", segment)
+		}
+		c, err := strconv.ParseUint(segment[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape in %q", segment)
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+	if strings.Contains(b.String(), "/") || escapeDirSegment(b.String()) != segment {
+		return "", fmt.Errorf("non-canonical escaping in %q", segment)
+	}
+	return b.String(), nil
+}
+
+// dirFileContents returns the contents of the file for an entry.
+func dirFileContents(valueTimestamp *ValueTimestamp) []byte {
+	header := strconv.FormatInt(valueTimestamp.Timestamp, 10)
+	if valueTimestamp.Value == nil {
+		header += " deleted"
+	}
+	if valueTimestamp.Flags != 0 {
+		header += " flags=" + strconv.FormatUint(uint64(valueTimestamp.Flags), 10)
+	}
+	if valueTimestamp.Value == nil {
+		return []byte(header + "\n")
+	}
+	return []byte(header + "\n" + *valueTimestamp.Value)
+}
+
+// parseDirFile parses the contents of the file for an entry.
+func parseDirFile(b []byte) (*ValueTimestamp, error) {
+	i := bytes.IndexByte(b, '\n')
+	if i < 0 {
+		return nil, fmt.Errorf("missing header line")
+	}
+	fields := strings.Fields(string(b[:i]))
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("missing timestamp")
+	}
+	timestamp, err := strconv.ParseInt(fields[0], 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid timestamp %q", fields[0])
+	}
+	valueTimestamp := &ValueTimestamp{Timestamp: timestamp}
+	deleted := false
+	for _, field := range fields[1:] {
+		switch {
+		case field == "deleted":
+			deleted = true
+		case strings.HasPrefix(field, "flags="):
+			flags, err := strconv.ParseUint(field[len("flags="):], 10, 32)
+			if err != nil {
+				return nil, fmt.Errorf("invalid %q", field)
+			}
+			valueTimestamp.Flags = Flags(flags)
+		default:
+			return nil, fmt.Errorf("unknown header field %q", field)
+		}
+	}
+	if deleted {
+		if len(b) > i+1 {
+			return nil, fmt.Errorf("deletion marker with a value")
+		}
+	} else {
+		value := string(b[i+1:])
+		valueTimestamp.Value = &value
+	}
+	return valueTimestamp, nil
+}
@@ -0,0 +1,78 @@
+package kvt_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func TestSaveDirRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store := kvt.Store{}
+	for _, key := range []string{"a", "a/b", "a/b/c", "", "/", "//x", ".hidden", "..", "a/../b", "sp ace", "ünï", "x.kvt", "100%", "Case", "case", "CASE"} {
+		store.SetTimestamped(key, "value of "+key+"\nsecond line\n", 1602000000123456789)
+	}
+	store.DeleteTimestamped("gone", 2)
+	store.SetTimestamped("flagged", "", 3)
+	store["flagged"].Flags = kvt.FlagPinned
+	if err := store.SaveDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := kvt.LoadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if a, b := loaded.String(), store.String(); a != b {
+		t.Fatal(a, b)
+	}
+	// Nothing escapes the directory.
+	if _, err = os.Stat(filepath.Join(filepath.Dir(dir), "b.kvt")); !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+
+	// Unchanged entries are not rewritten, and removed ones are deleted along
+	// with their emptied directories.
+	path := filepath.Join(dir, "a.kvt")
+	old := time.Unix(1, 0)
+	if err = os.Chtimes(path, old, old); err != nil {
+		t.Fatal(err)
+	}
+	delete(store, "a/b/c")
+	if err = store.SaveDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path); err != nil || !info.ModTime().Equal(old) {
+		t.Fatal("unchanged file was rewritten", err)
+	}
+	if _, err = os.Stat(filepath.Join(dir, "a", "b")); !os.IsNotExist(err) {
+		t.Fatal("emptied directory left behind", err)
+	}
+	if loaded, err = kvt.LoadDir(dir); err != nil || loaded.String() != store.String() {
+		t.Fatal(loaded, err)
+	}
+}
+
+func TestLoadDirJunk(t *testing.T) {
+	for name, contents := range map[string]string{
+		"x.kvt":   "no header line",
+		"y.kvt":   "abc\nvalue",
+		"z.kvt":   "1 deleted\nvalue",
+		"w.kvt":   "1 bogus\n",
+		"%zz.kvt": "1\nvalue",
+		"A.kvt":   "1\nvalue",
+		"%61.kvt": "1\nvalue",
+		"%2f.kvt": "1\nvalue",
+		"%2F.kvt": "1\nvalue",
+	} {
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(contents), 0666); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := kvt.LoadDir(dir); err == nil {
+			t.Errorf("expected error from %s: %q", name, contents)
+		}
+	}
+}
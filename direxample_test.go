@@ -0,0 +1,40 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_SaveDir() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := kvt.Store{}
+	store.SetTimestamped("service/web/port", "8080", 1)
+	store.SetTimestamped("service/web/host name", "example.com", 1)
+	store.DeleteTimestamped("service/old", 2)
+	if err = store.SaveDir(dir); err != nil {
+		panic(err)
+	}
+	filepath.Walk(dir, func(path string, info os.FileInfo, err error) error {
+		if !info.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			b, _ := os.ReadFile(path)
+			fmt.Printf("%s: %q\n", filepath.ToSlash(rel), b)
+		}
+		return nil
+	})
+	fmt.Println(kvt.LoadDir(dir))
+
+	// Output:
+	// service/old.kvt: "2 deleted\n"
+	// service/web/host%20name.kvt: "1\nexample.com"
+	// service/web/port.kvt: "1\n8080"
+	// {"service/old":[null,2],"service/web/host name":["example.com",1],"service/web/port":["8080",1]} <nil>
+}
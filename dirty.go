@@ -0,0 +1,95 @@
+package kvt
+
+import (
+	"encoding/json"
+	"io"
+	"sort"
+	"sync"
+)
+
+// DirtyTracker records which keys of a SyncStore have changed since the last
+// save, so that only those entries need be written; see SyncStore.TrackDirty.
+type DirtyTracker struct {
+	syncStore *SyncStore
+	lock      sync.Mutex
+	keys      map[string]bool
+	// stopListening unregisters changed from the SyncStore.
+	stopListening func()
+}
+
+// TrackDirty starts a DirtyTracker noting every key whose entry changes, by
+// any write or Absorb, from now on. Call Stop on the DirtyTracker when it is
+// no longer needed.
+func (syncStore *SyncStore) TrackDirty() *DirtyTracker {
+	dirtyTracker := &DirtyTracker{syncStore: syncStore, keys: map[string]bool{}}
+	dirtyTracker.stopListening = syncStore.listen(&listener{changed: dirtyTracker.changed})
+	return dirtyTracker
+}
+
+// Stop stops noting changed keys; the keys already noted are kept. It is safe
+// to call Stop more than once.
+func (dirtyTracker *DirtyTracker) Stop() {
+	dirtyTracker.stopListening()
+}
+
+// changed is the SyncStore hook for each batch of changes.
+func (dirtyTracker *DirtyTracker) changed(changes []Change) {
+	dirtyTracker.lock.Lock()
+	for _, change := range changes {
+		dirtyTracker.keys[change.Key] = true
+	}
+	dirtyTracker.lock.Unlock()
+}
+
+// Keys returns the sorted keys that have changed since the last SaveDelta or
+// Reset.
+func (dirtyTracker *DirtyTracker) Keys() []string {
+	dirtyTracker.lock.Lock()
+	ks := make([]string, 0, len(dirtyTracker.keys))
+	for key := range dirtyTracker.keys {
+		ks = append(ks, key)
+	}
+	dirtyTracker.lock.Unlock()
+	sort.Strings(ks)
+	return ks
+}
+
+// Reset forgets the changed keys, such as after saving the whole store.
+func (dirtyTracker *DirtyTracker) Reset() {
+	dirtyTracker.lock.Lock()
+	dirtyTracker.keys = map[string]bool{}
+	dirtyTracker.lock.Unlock()
+}
+
+// SaveDelta writes the current entries for the changed keys to w as a JSON
+// encoded Store and then forgets those keys. Absorbing each delta, in any
+// order, into the store last saved in full restores the store. If writing
+// fails, the keys are kept so the next SaveDelta includes them again.
+func (dirtyTracker *DirtyTracker) SaveDelta(w io.Writer) error {
+	dirtyTracker.lock.Lock()
+	keys := dirtyTracker.keys
+	dirtyTracker.keys = map[string]bool{}
+	dirtyTracker.lock.Unlock()
+	delta := make(Store, len(keys))
+	dirtyTracker.syncStore.read(func(store Store) {
+		for key := range keys {
+			// A purged deletion marker has no entry left to save.
+			if valueTimestamp := store[key]; valueTimestamp != nil {
+				valueTimestampCopy := *valueTimestamp
+				delta[key] = &valueTimestampCopy
+			}
+		}
+	})
+	b, err := json.Marshal(delta)
+	if err == nil {
+		_, err = w.Write(b)
+	}
+	if err != nil {
+		dirtyTracker.lock.Lock()
+		for key := range keys {
+			dirtyTracker.keys[key] = true
+		}
+		dirtyTracker.lock.Unlock()
+	}
+	return err
+}
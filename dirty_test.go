@@ -0,0 +1,20 @@
+package kvt_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestDirtyTrackerStop(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	dirtyTracker := syncStore.TrackDirty()
+	syncStore.SetTimestamped("A", "one", 1)
+	dirtyTracker.Stop()
+	dirtyTracker.Stop()
+	syncStore.SetTimestamped("B", "two", 2)
+	if keys := dirtyTracker.Keys(); !reflect.DeepEqual(keys, []string{"A"}) {
+		t.Fatal(keys)
+	}
+}
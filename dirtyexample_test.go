@@ -0,0 +1,49 @@
+package kvt_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleDirtyTracker_SaveDelta() {
+	syncStore := kvt.NewSyncStore(nil)
+	for i := 0; i < 1000; i++ {
+		syncStore.SetTimestamped(fmt.Sprintf("k%03d", i), "v", 1)
+	}
+	var full bytes.Buffer
+	full.WriteString(syncStore.String())
+
+	// After the full save, only changed entries need writing.
+	dirtyTracker := syncStore.TrackDirty()
+	syncStore.SetTimestamped("k123", "changed", 2)
+	syncStore.DeleteTimestamped("k456", 2)
+	syncStore.SetTimestamped("k789", "old", 0)
+	fmt.Println(dirtyTracker.Keys())
+	var delta bytes.Buffer
+	if err := dirtyTracker.SaveDelta(&delta); err != nil {
+		panic(err)
+	}
+	fmt.Println(delta.String())
+	fmt.Println(dirtyTracker.Keys())
+
+	// Restoring absorbs the delta into the full save.
+	store := kvt.Store{}
+	json.Unmarshal(full.Bytes(), &store)
+	store2 := kvt.Store{}
+	json.Unmarshal(delta.Bytes(), &store2)
+	store.Absorb(store2)
+	fmt.Println(store.Hash() == syncStore.Hash())
+	dirtyTracker.SaveDelta(os.Stdout)
+	fmt.Println()
+
+	// Output:
+	// [k123 k456]
+	// {"k123":["changed",2],"k456":[null,2]}
+	// []
+	// true
+	// {}
+}
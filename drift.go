@@ -0,0 +1,98 @@
+package kvt
+
+import (
+	"sort"
+	"strings"
+)
+
+// DriftKind is the kind of difference a DriftChange records.
+type DriftKind string
+
+const (
+	// DriftAdded means the key has a value now but didn't in the baseline.
+	DriftAdded DriftKind = "added"
+	// DriftRemoved means the key had a value in the baseline but doesn't now.
+	DriftRemoved DriftKind = "removed"
+	// DriftModified means the key's value differs from the baseline's.
+	DriftModified DriftKind = "modified"
+)
+
+// DriftChange is one key's difference from the baseline in a DriftReport.
+type DriftChange struct {
+	Key  string
+	Kind DriftKind
+	// Expected is true if the key is in one of the expected churn prefixes.
+	Expected bool
+}
+
+// DriftReport is the result of Store.Drift.
+type DriftReport struct {
+	// Changes are in key order.
+	Changes    []DriftChange
+	Expected   int
+	Unexpected int
+	// Score is the unexpected changes as a fraction of all the keys with
+	// values in either the store or the baseline, from 0 for no unexpected
+	// drift to 1 when every key changed unexpectedly.
+	Score float64
+}
+
+// Drift compares the values in the store against those of a baseline, such
+// as a snapshot saved at the last change review. Changes to keys beginning
+// with any of the expectedPrefixes are reported but marked as expected and
+// don't count toward the score. Only values are compared; an entry whose
+// timestamp changed but whose value didn't is not drift.
+func (store Store) Drift(baseline Store, expectedPrefixes ...string) *DriftReport {
+	report := &DriftReport{}
+	ks := store.sortedKeys()
+	for k := range baseline {
+		if _, ok := store[k]; !ok {
+			ks = append(ks, k)
+		}
+	}
+	sort.Strings(ks)
+	var total int
+	for _, k := range ks {
+		current, inStore := liveValue(store, k)
+		previous, inBaseline := liveValue(baseline, k)
+		if inStore || inBaseline {
+			total++
+		}
+		var kind DriftKind
+		switch {
+		case inStore && !inBaseline:
+			kind = DriftAdded
+		case !inStore && inBaseline:
+			kind = DriftRemoved
+		case inStore && inBaseline && current != previous:
+			kind = DriftModified
+		default:
+			continue
+		}
+		change := DriftChange{Key: k, Kind: kind}
+		for _, prefix := range expectedPrefixes {
+			if strings.HasPrefix(k, prefix) {
+				change.Expected = true
+				break
+			}
+		}
+		if change.Expected {
+			report.Expected++
+		} else {
+			report.Unexpected++
+		}
+		report.Changes = append(report.Changes, change)
+	}
+	if total > 0 {
+		report.Score = float64(report.Unexpected) / float64(total)
+	}
+	return report
+}
+
+// liveValue returns the key's value in store and whether it has one.
+func liveValue(store Store, key string) (string, bool) {
+	if valueTimestamp := store[key]; valueTimestamp != nil && valueTimestamp.Value != nil {
+		return *valueTimestamp.Value, true
+	}
+	return "", false
+}
@@ -0,0 +1,35 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_Drift() {
+	baseline := kvt.Store{}
+	baseline.SetTimestamped("config/A", "one", 1)
+	baseline.SetTimestamped("config/B", "two", 1)
+	baseline.SetTimestamped("config/C", "three", 1)
+	baseline.SetTimestamped("heartbeat/node1", "1", 1)
+
+	store := baseline.Copy()
+	store.SetTimestamped("config/A", "uno", 2)
+	store.DeleteTimestamped("config/B", 2)
+	store.SetTimestamped("config/C", "three", 2) // Same value; not drift.
+	store.SetTimestamped("heartbeat/node1", "2", 2)
+	store.SetTimestamped("heartbeat/node2", "1", 2)
+
+	report := store.Drift(baseline, "heartbeat/")
+	for _, change := range report.Changes {
+		fmt.Println(change.Key, change.Kind, change.Expected)
+	}
+	fmt.Println(report.Expected, report.Unexpected, report.Score)
+
+	// Output:
+	// config/A modified false
+	// config/B removed false
+	// heartbeat/node1 modified true
+	// heartbeat/node2 added true
+	// 2 2 0.4
+}
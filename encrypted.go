@@ -0,0 +1,81 @@
+package kvt
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+)
+
+// encryptedHeader begins every file written by SaveFileEncrypted; it is also
+// authenticated along with the contents.
+const encryptedHeader = "kvt-aes-gcm 1\n"
+
+// ErrDecrypt is returned by LoadFileEncrypted when the file can't be
+// decrypted, whether because the key is wrong or the file has been altered.
+var ErrDecrypt = errors.New("kvt: unable to decrypt; wrong key or corrupted file")
+
+// SaveFileEncrypted is like SaveFile but encrypts the file with AES-GCM using
+// key, which must be 16, 24, or 32 bytes long to select AES-128, AES-192, or
+// AES-256. Each save uses a new random nonce.
+func (store Store) SaveFileEncrypted(path string, key []byte) error {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return err
+	}
+	plaintext, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err = rand.Read(nonce); err != nil {
+		return err
+	}
+	b := make([]byte, 0, len(encryptedHeader)+len(nonce)+len(plaintext)+aead.Overhead())
+	b = append(b, encryptedHeader...)
+	b = append(b, nonce...)
+	b = aead.Seal(b, nonce, plaintext, []byte(encryptedHeader))
+	return writeFileAtomic(path, b)
+}
+
+// LoadFileEncrypted reads a store saved by SaveFileEncrypted with the same
+// key, returning ErrDecrypt if the key is wrong or the file has been altered.
+func LoadFileEncrypted(path string, key []byte) (Store, error) {
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if !bytes.HasPrefix(b, []byte(encryptedHeader)) {
+		return nil, fmt.Errorf("%s: not an encrypted kvt file", path)
+	}
+	b = b[len(encryptedHeader):]
+	if len(b) < aead.NonceSize() {
+		return nil, ErrDecrypt
+	}
+	plaintext, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], []byte(encryptedHeader))
+	if err != nil {
+		return nil, ErrDecrypt
+	}
+	store := Store{}
+	if err = json.Unmarshal(plaintext, &store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// newAEAD returns AES-GCM for key.
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
@@ -0,0 +1,39 @@
+package kvt_test
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestLoadFileEncryptedTampered(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.kvt")
+	key := bytes.Repeat([]byte{1}, 16)
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	if err := store.SaveFileEncrypted(path, key); err != nil {
+		t.Fatal(err)
+	}
+	b, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, altered := range [][]byte{
+		append(append([]byte{}, b[:len(b)-1]...), b[len(b)-1]^1),
+		b[:len(b)-5],
+		b[:20],
+	} {
+		if err = os.WriteFile(path, altered, 0666); err != nil {
+			t.Fatal(err)
+		}
+		if _, err = kvt.LoadFileEncrypted(path, key); err != kvt.ErrDecrypt {
+			t.Error(err)
+		}
+	}
+	if err = store.SaveFileEncrypted(path, []byte("short")); err == nil {
+		t.Error("expected error for bad key size")
+	}
+}
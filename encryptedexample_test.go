@@ -0,0 +1,36 @@
+package kvt_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_SaveFileEncrypted() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "secrets.kvt")
+	key := bytes.Repeat([]byte{7}, 32)
+
+	store := kvt.Store{}
+	store.SetTimestamped("db/password", "hunter2", 1)
+	fmt.Println(store.SaveFileEncrypted(path, key))
+	b, _ := os.ReadFile(path)
+	fmt.Println(bytes.Contains(b, []byte("hunter2")))
+
+	fmt.Println(kvt.LoadFileEncrypted(path, key))
+	_, err = kvt.LoadFileEncrypted(path, bytes.Repeat([]byte{8}, 32))
+	fmt.Println(err)
+
+	// Output:
+	// <nil>
+	// false
+	// {"db/password":["hunter2",1]} <nil>
+	// kvt: unable to decrypt; wrong key or corrupted file
+}
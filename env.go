@@ -0,0 +1,42 @@
+package kvt
+
+import (
+	"os"
+	"strings"
+)
+
+// ToEnv returns the store's values as environment variables, "name=value" in
+// key order, with each name being prefix+key; deletion markers are omitted.
+// The result can be appended to an exec.Cmd's Env to pass a store of
+// configuration to a child process, which can rebuild it with FromEnv.
+//
+// Keys are used verbatim, so to be usable from shells they should be valid
+// variable names, such as "PORT" or "DB_HOST". Entries whose key contains an
+// equal sign, or whose key or value contains a NUL byte, can't be represented
+// and are omitted.
+func (store Store) ToEnv(prefix string) []string {
+	var env []string
+	for _, key := range store.sortedKeys() {
+		valueTimestamp := store[key]
+		if valueTimestamp.Value == nil || strings.ContainsAny(key, "=\x00") || strings.ContainsRune(*valueTimestamp.Value, 0) {
+			continue
+		}
+		env = append(env, prefix+key+"="+*valueTimestamp.Value)
+	}
+	return env
+}
+
+// FromEnv returns a store of the process's environment variables whose names
+// begin with prefix, keyed by the rest of the name, all with the timestamp
+// given; see ToEnv.
+func FromEnv(prefix string, timestamp int64) Store {
+	store := Store{}
+	for _, variable := range os.Environ() {
+		i := strings.IndexByte(variable, '=')
+		if i < len(prefix) || !strings.HasPrefix(variable, prefix) {
+			continue
+		}
+		store.SetTimestamped(variable[len(prefix):i], variable[i+1:], timestamp)
+	}
+	return store
+}
@@ -0,0 +1,30 @@
+package kvt_test
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestFromEnv(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("PORT", "8080", 1)
+	store.SetTimestamped("EMPTY", "", 1)
+	store.SetTimestamped("EQUALS", "a=b", 1)
+	store.SetTimestamped("BAD=KEY", "x", 1)
+	store.SetTimestamped("NUL", "x\x00y", 1)
+	for _, variable := range store.ToEnv("KVT_TEST_ENV_") {
+		parts := strings.SplitN(variable, "=", 2)
+		t.Setenv(parts[0], parts[1])
+	}
+	t.Setenv("KVT_TEST_ENV", "not in the store")
+	loaded := kvt.FromEnv("KVT_TEST_ENV_", 5)
+	if a, b := loaded.String(), `{"EMPTY":["",5],"EQUALS":["a=b",5],"PORT":["8080",5]}`; a != b {
+		t.Fatal(a, b)
+	}
+	if _, ok := kvt.FromEnv("", 5)["PATH"]; !ok && os.Getenv("PATH") != "" {
+		t.Fatal("PATH missing with empty prefix")
+	}
+}
@@ -0,0 +1,25 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_ToEnv() {
+	store := kvt.Store{}
+	store.SetTimestamped("PORT", "8080", 1)
+	store.SetTimestamped("DB_HOST", "db.example.com", 1)
+	store.DeleteTimestamped("OLD", 2)
+	env := store.ToEnv("APP_")
+	fmt.Println(env)
+
+	// The child process would call kvt.FromEnv("APP_", kvt.Now()).
+	cmd := exec.Command("child")
+	cmd.Env = append(os.Environ(), env...)
+
+	// Output:
+	// [APP_DB_HOST=db.example.com APP_PORT=8080]
+}
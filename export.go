@@ -0,0 +1,237 @@
+package kvt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+)
+
+// Export writes the store's entries to w one per line, in key order, each
+// line a JSON array of [key,value,timestamp], or [key,value,timestamp,flags]
+// when there are flags, with a null value for deletion markers. Entries are
+// encoded as they are written, so exporting a large store doesn't need a
+// second copy of it in memory.
+func (store Store) Export(w io.Writer) error {
+	return store.export(w, writeExportLine)
+}
+
+// ExportNDJSON is like Export but writes each line as a JSON object, such as
+// {"k":"A","v":"one","t":1}, plus "f" when there are flags, which suits log
+// shippers and tools such as jq. Import reads either form of line, even
+// mixed in the same input, so objects can be appended to an exported file.
+func (store Store) ExportNDJSON(w io.Writer) error {
+	return store.export(w, writeNDJSONLine)
+}
+
+// export writes each entry, in key order, with writeLine.
+func (store Store) export(w io.Writer, writeLine func(writer *bufio.Writer, key string, valueTimestamp *ValueTimestamp) error) error {
+	writer := bufio.NewWriter(w)
+	for _, key := range store.sortedKeys() {
+		if err := writeLine(writer, key, store[key]); err != nil {
+			return err
+		}
+	}
+	return writer.Flush()
+}
+
+// writeExportLine writes one Export line.
+func writeExportLine(writer *bufio.Writer, key string, valueTimestamp *ValueTimestamp) error {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	v := []byte("null")
+	if valueTimestamp.Value != nil {
+		if v, err = json.Marshal(*valueTimestamp.Value); err != nil {
+			return err
+		}
+	}
+	writer.WriteByte('[')
+	writer.Write(k)
+	writer.WriteByte(',')
+	writer.Write(v)
+	writer.WriteByte(',')
+	writer.WriteString(strconv.FormatInt(valueTimestamp.Timestamp, 10))
+	if valueTimestamp.Flags != 0 {
+		writer.WriteByte(',')
+		writer.WriteString(strconv.FormatUint(uint64(valueTimestamp.Flags), 10))
+	}
+	_, err = writer.WriteString("]\n")
+	return err
+}
+
+// writeNDJSONLine writes one ExportNDJSON line.
+func writeNDJSONLine(writer *bufio.Writer, key string, valueTimestamp *ValueTimestamp) error {
+	k, err := json.Marshal(key)
+	if err != nil {
+		return err
+	}
+	v := []byte("null")
+	if valueTimestamp.Value != nil {
+		if v, err = json.Marshal(*valueTimestamp.Value); err != nil {
+			return err
+		}
+	}
+	writer.WriteString(`{"k":`)
+	writer.Write(k)
+	writer.WriteString(`,"v":`)
+	writer.Write(v)
+	writer.WriteString(`,"t":`)
+	writer.WriteString(strconv.FormatInt(valueTimestamp.Timestamp, 10))
+	if valueTimestamp.Flags != 0 {
+		writer.WriteString(`,"f":`)
+		writer.WriteString(strconv.FormatUint(uint64(valueTimestamp.Flags), 10))
+	}
+	_, err = writer.WriteString("}\n")
+	return err
+}
+
+// Import reads lines written by Export or ExportNDJSON from r, absorbing each
+// entry into the store as it is read, and returns how many entries were read.
+// On error, the entries read before the bad line will already have been
+// absorbed.
+func (store Store) Import(r io.Reader) (int, error) {
+	return importLines(r, func(key string, valueTimestamp *ValueTimestamp) {
+		store.absorbEntry(key, valueTimestamp)
+	})
+}
+
+// importLines calls fn with each entry read from the Export lines in r.
+func importLines(r io.Reader, fn func(key string, valueTimestamp *ValueTimestamp)) (int, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	n := 0
+	for line := 1; scanner.Scan(); line++ {
+		if len(bytes.TrimSpace(scanner.Bytes())) == 0 {
+			continue
+		}
+		key, valueTimestamp, err := parseExportLine(scanner.Bytes())
+		if err != nil {
+			return n, fmt.Errorf("invalid export line %d: %s", line, err)
+		}
+		fn(key, valueTimestamp)
+		n++
+	}
+	return n, scanner.Err()
+}
+
+// parseExportLine parses one Export line. Numbers are decoded exactly, rather
+// than by way of float64, so timestamps keep their full precision.
+func parseExportLine(b []byte) (string, *ValueTimestamp, error) {
+	if trimmed := bytes.TrimLeft(b, " \t"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return parseNDJSONLine(b)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.UseNumber()
+	var fields []interface{}
+	if err := decoder.Decode(&fields); err != nil {
+		return "", nil, err
+	}
+	if len(fields) != 3 && len(fields) != 4 {
+		return "", nil, fmt.Errorf("expected [key,value,timestamp] or [key,value,timestamp,flags]")
+	}
+	key, ok := fields[0].(string)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid key")
+	}
+	valueTimestamp := &ValueTimestamp{}
+	if fields[1] != nil {
+		value, ok := fields[1].(string)
+		if !ok {
+			return "", nil, fmt.Errorf("invalid value")
+		}
+		valueTimestamp.Value = &value
+	}
+	number, ok := fields[2].(json.Number)
+	if !ok {
+		return "", nil, fmt.Errorf("invalid timestamp")
+	}
+	timestamp, err := strconv.ParseInt(string(number), 10, 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid timestamp")
+	}
+	valueTimestamp.Timestamp = timestamp
+	if len(fields) == 4 {
+		number, ok = fields[3].(json.Number)
+		if !ok {
+			return "", nil, fmt.Errorf("invalid flags")
+		}
+		flags, err := strconv.ParseUint(string(number), 10, 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid flags")
+		}
+		valueTimestamp.Flags = Flags(flags)
+	}
+	return key, valueTimestamp, nil
+}
+
+// parseNDJSONLine parses one ExportNDJSON line, decoding numbers exactly as
+// parseExportLine does.
+func parseNDJSONLine(b []byte) (string, *ValueTimestamp, error) {
+	var object struct {
+		Key       *string         `json:"k"`
+		Value     json.RawMessage `json:"v"`
+		Timestamp json.RawMessage `json:"t"`
+		Flags     json.RawMessage `json:"f"`
+	}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&object); err != nil {
+		return "", nil, err
+	}
+	if object.Key == nil || object.Value == nil || object.Timestamp == nil {
+		return "", nil, fmt.Errorf(`expected {"k":key,"v":value,"t":timestamp}`)
+	}
+	valueTimestamp := &ValueTimestamp{}
+	if string(object.Value) != "null" {
+		var value string
+		if err := json.Unmarshal(object.Value, &value); err != nil {
+			return "", nil, fmt.Errorf("invalid value")
+		}
+		valueTimestamp.Value = &value
+	}
+	timestamp, err := strconv.ParseInt(string(object.Timestamp), 10, 64)
+	if err != nil {
+		return "", nil, fmt.Errorf("invalid timestamp")
+	}
+	valueTimestamp.Timestamp = timestamp
+	if object.Flags != nil {
+		flags, err := strconv.ParseUint(string(object.Flags), 10, 32)
+		if err != nil {
+			return "", nil, fmt.Errorf("invalid flags")
+		}
+		valueTimestamp.Flags = Flags(flags)
+	}
+	return *object.Key, valueTimestamp, nil
+}
+
+// Export writes the entries to w one per line; see Store.Export. It exports
+// a Snapshot, so writers are not held up while exporting, but the next write
+// copies the store; see Snapshot.
+func (syncStore *SyncStore) Export(w io.Writer) error {
+	return syncStore.Snapshot().Export(w)
+}
+
+// ExportNDJSON writes the entries to w as JSON objects one per line; see
+// Store.ExportNDJSON. Like Export, it exports a Snapshot.
+func (syncStore *SyncStore) ExportNDJSON(w io.Writer) error {
+	return syncStore.Snapshot().ExportNDJSON(w)
+}
+
+// Import absorbs the entries read from r; see Store.Import. Entries are
+// absorbed in batches, so the write lock is not held while reading.
+func (syncStore *SyncStore) Import(r io.Reader) (int, error) {
+	batch := Store{}
+	n, err := importLines(r, func(key string, valueTimestamp *ValueTimestamp) {
+		batch.absorbEntry(key, valueTimestamp)
+		if len(batch) >= contextCheckInterval {
+			syncStore.Absorb(batch)
+			batch = Store{}
+		}
+	})
+	syncStore.Absorb(batch)
+	return n, err
+}
@@ -0,0 +1,52 @@
+package kvt_test
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestImportJunk(t *testing.T) {
+	for _, s := range []string{
+		`not json`,
+		`["A","one"]`,
+		`[1,"one",1]`,
+		`["A",1,1]`,
+		`["A","one",1.5]`,
+		`["A","one",1,-1]`,
+		`["A","one",1,4294967296]`,
+		`{"k":"A","v":"one"}`,
+		`{"v":"one","t":1}`,
+		`{"k":"A","t":1}`,
+		`{"k":1,"v":"one","t":1}`,
+		`{"k":"A","v":1,"t":1}`,
+		`{"k":"A","v":"one","t":"1"}`,
+		`{"k":"A","v":"one","t":1,"f":-1}`,
+		`{"k":"A","v":"one","t":1,"x":1}`,
+	} {
+		if _, err := (kvt.Store{}).Import(strings.NewReader(s)); err == nil {
+			t.Errorf("expected error from %s", s)
+		}
+	}
+}
+
+func TestSyncStoreExportImport(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	for i := 0; i < 3000; i++ {
+		syncStore.SetTimestamped(fmt.Sprintf("k%d", i), "v", int64(i))
+	}
+	var sb strings.Builder
+	if err := syncStore.Export(&sb); err != nil {
+		t.Fatal(err)
+	}
+	syncStore2 := kvt.NewSyncStore(nil)
+	n, err := syncStore2.Import(strings.NewReader("\n" + sb.String()))
+	if n != 3000 || err != nil {
+		t.Fatal(n, err)
+	}
+	if syncStore.Hash() != syncStore2.Hash() {
+		t.Fatal("hash mismatch")
+	}
+}
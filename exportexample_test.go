@@ -0,0 +1,57 @@
+package kvt_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_Export() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1602000000123456789)
+	store.DeleteTimestamped("B", 2)
+	store.SetTimestamped("C", "three", 3)
+	store["C"].Flags = kvt.FlagPinned
+	var buf bytes.Buffer
+	store.Export(&buf)
+	os.Stdout.Write(buf.Bytes())
+
+	// Import keeps the timestamps exact.
+	store2 := kvt.Store{}
+	fmt.Println(store2.Import(&buf))
+	fmt.Println(store2["A"].Timestamp, store2.Hash() == store.Hash())
+
+	// Output:
+	// ["A","one",1602000000123456789]
+	// ["B",null,2]
+	// ["C","three",3,4]
+	// 3 <nil>
+	// 1602000000123456789 true
+}
+
+func ExampleStore_ExportNDJSON() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1602000000123456789)
+	store.DeleteTimestamped("B", 2)
+	store.SetTimestamped("C", "three", 3)
+	store["C"].Flags = kvt.FlagPinned
+	var buf bytes.Buffer
+	store.ExportNDJSON(&buf)
+	os.Stdout.Write(buf.Bytes())
+
+	// Lines can be appended, in either form, and are absorbed in turn.
+	buf.WriteString(`{"k":"A","v":"newer","t":1602000000123456790}` + "\n")
+	buf.WriteString(`["B","back",4]` + "\n")
+	store2 := kvt.Store{}
+	fmt.Println(store2.Import(&buf))
+	fmt.Println(store2)
+
+	// Output:
+	// {"k":"A","v":"one","t":1602000000123456789}
+	// {"k":"B","v":null,"t":2}
+	// {"k":"C","v":"three","t":3,"f":4}
+	// 5 <nil>
+	// {"A":["newer",1602000000123456790],"B":["back",4],"C":["three",3,4]}
+}
@@ -0,0 +1,121 @@
+package kvt
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// MergeSaveFile saves the store to the file at path, first absorbing whatever
+// the file already contains. An advisory lock on path+".lock" is held for the
+// duration so that several processes sharing the same file don't discard each
+// other's entries. Afterwards, store will also hold any newer entries that had
+// been saved by others.
+func (store Store) MergeSaveFile(path string) error {
+	unlock, err := lockFile(path + ".lock")
+	if err != nil {
+		return err
+	}
+	defer unlock()
+	existing, err := LoadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	store.Absorb(existing)
+	return store.SaveFile(path)
+}
+
+// LoadFile reads the JSON encoded store from the file at path, as written by
+// SaveFile, SaveFileCompressed, or SaveFileChecksummed; the format is detected
+// automatically. Checksummed files that are damaged give a *CorruptError.
+func LoadFile(path string) (Store, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	if bytes.HasPrefix(b, gzipMagic) {
+		r, err := gzip.NewReader(bytes.NewReader(b))
+		if err != nil {
+			return nil, err
+		}
+		if b, err = io.ReadAll(r); err != nil {
+			return nil, err
+		}
+	}
+	if isChecksummed(b) {
+		store, err := readChecksummed(b)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
+		return store, nil
+	}
+	store := Store{}
+	if err = json.Unmarshal(b, &store); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// SaveFile writes the JSON encoded store to a temporary file and then renames
+// it to path, so readers never see a partially written file. Use
+// MergeSaveFile instead if other processes may also be saving to path.
+func (store Store) SaveFile(path string) error {
+	b, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path, b)
+}
+
+// gzipMagic begins every gzip stream; JSON never starts with these bytes.
+var gzipMagic = []byte{0x1f, 0x8b}
+
+// SaveFileCompressed is like SaveFile but gzip compresses the file at the
+// level given, such as gzip.BestSpeed or gzip.DefaultCompression. JSON
+// encoded stores, with their repetitive keys, usually compress well.
+func (store Store) SaveFileCompressed(path string, level int) error {
+	b, err := json.Marshal(store)
+	if err != nil {
+		return err
+	}
+	var buf bytes.Buffer
+	w, err := gzip.NewWriterLevel(&buf, level)
+	if err != nil {
+		return err
+	}
+	if _, err = w.Write(b); err != nil {
+		return err
+	}
+	if err = w.Close(); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// writeFileAtomic writes b to a temporary file in the same directory as path,
+// syncs it, renames it over path, and syncs the directory, so the new file is
+// durably in place once it returns.
+func writeFileAtomic(path string, b []byte) error {
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp")
+	if err != nil {
+		return err
+	}
+	if _, err = f.Write(b); err == nil {
+		err = f.Sync()
+	}
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	if err == nil {
+		err = os.Rename(f.Name(), path)
+	}
+	if err != nil {
+		os.Remove(f.Name())
+		return err
+	}
+	return syncDir(filepath.Dir(path))
+}
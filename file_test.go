@@ -0,0 +1,60 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestMergeSaveFileConcurrent(t *testing.T) {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+	var wg sync.WaitGroup
+	errs := make(chan error, 10)
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			store := kvt.Store{}
+			store.SetTimestamped(fmt.Sprintf("key%d", i), "value", 1)
+			errs <- store.MergeSaveFile(path)
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+	for err := range errs {
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	store := kvt.Store{}
+	if err = store.MergeSaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	if len(store) != 10 {
+		t.Fatal(store)
+	}
+}
+
+func TestMergeSaveFileJunk(t *testing.T) {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+	if err = os.WriteFile(path, []byte("junk"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if err = (kvt.Store{}).MergeSaveFile(path); err == nil {
+		t.Fatal("expected error")
+	}
+}
@@ -0,0 +1,121 @@
+package kvt_test
+
+import (
+	"compress/gzip"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_MergeSaveFile() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	// Two processes each save their own changes to the same file.
+	store1 := kvt.Store{}
+	store1.SetTimestamped("A", "one", 1)
+	store1.SetTimestamped("B", "two", 1)
+	fmt.Println(store1.MergeSaveFile(path))
+	store2 := kvt.Store{}
+	store2.DeleteTimestamped("B", 2)
+	store2.SetTimestamped("C", "three", 2)
+	fmt.Println(store2.MergeSaveFile(path))
+
+	// The second save merged in the first's entries rather than replacing them.
+	fmt.Println("Store2:", store2.SimpleString())
+	b, _ := os.ReadFile(path)
+	fmt.Println("File:", string(b))
+
+	// Output:
+	// <nil>
+	// <nil>
+	// Store2: A=one,B/deleted,C=three
+	// File: {"A":["one",1],"B":[null,2],"C":["three",2]}
+}
+
+func ExampleStore_SaveFile() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	fmt.Println(store.SaveFile(path))
+	loaded, err := kvt.LoadFile(path)
+	fmt.Println(loaded, err)
+
+	_, err = kvt.LoadFile(filepath.Join(dir, "missing.json"))
+	fmt.Println(os.IsNotExist(err))
+
+	// Output:
+	// <nil>
+	// {"A":["one",1],"B":[null,2]} <nil>
+	// true
+}
+
+func ExampleSyncStore_LoadFile() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.DeleteTimestamped("B", 2)
+	syncStore.DeleteTimestamped("C", 5)
+	syncStore.Purge(3)
+	fmt.Println(syncStore.SaveFile(path))
+
+	// Meanwhile, a stale copy of B's deletion marker turns up before the
+	// restore; the restored cutoff purges it again.
+	restored := kvt.NewSyncStore(nil)
+	restored.DeleteTimestamped("B", 2)
+	fmt.Println(restored.LoadFile(path))
+	fmt.Println(restored, restored.PurgeInfo().Cutoff)
+
+	// Output:
+	// <nil>
+	// <nil>
+	// {"A":["one",1],"C":[null,5]} 3
+}
+
+func ExampleStore_SaveFileCompressed() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+
+	store := kvt.Store{}
+	for i := 0; i < 1000; i++ {
+		store.SetTimestamped(fmt.Sprintf("service/instance-%04d/status", i), "healthy", 1)
+	}
+	path := filepath.Join(dir, "store.json")
+	compressedPath := filepath.Join(dir, "store.json.gz")
+	store.SaveFile(path)
+	fmt.Println(store.SaveFileCompressed(compressedPath, gzip.BestCompression))
+	info, _ := os.Stat(path)
+	compressedInfo, _ := os.Stat(compressedPath)
+	fmt.Println(compressedInfo.Size()*10 < info.Size())
+
+	// LoadFile detects the compression itself.
+	loaded, err := kvt.LoadFile(compressedPath)
+	fmt.Println(loaded.Hash() == store.Hash(), err)
+
+	// Output:
+	// <nil>
+	// true
+	// true <nil>
+}
@@ -0,0 +1,75 @@
+package kvt
+
+import (
+	"os"
+	"sync"
+	"time"
+)
+
+// FileWatcher absorbs a file into a SyncStore whenever the file changes; see
+// SyncStore.WatchFile.
+type FileWatcher struct {
+	*background
+	syncStore *SyncStore
+	path      string
+	lock      sync.Mutex
+	// info is of the file as last absorbed.
+	info os.FileInfo
+	err  error
+}
+
+// WatchFile absorbs the store in the file at path, as read by LoadFile, into
+// syncStore now and again whenever the file's size or modification time
+// changes, or the file is replaced by another, checking every interval.
+// Together with SaveFile or MergeSaveFile, this lets several local processes
+// share one store file. The file is polled rather than watched with OS
+// notifications, so changes are seen within an interval. A missing file is not
+// an error; it is absorbed once it appears. An interval of 0 or less is
+// treated as one second. Call Stop or Close on the FileWatcher when it is no
+// longer needed.
+func (syncStore *SyncStore) WatchFile(path string, interval time.Duration) *FileWatcher {
+	fileWatcher := &FileWatcher{syncStore: syncStore, path: path}
+	fileWatcher.check()
+	fileWatcher.background = startBackground(interval, fileWatcher.check)
+	return fileWatcher
+}
+
+// check absorbs the file if it has changed since last checked.
+func (fileWatcher *FileWatcher) check() {
+	fileWatcher.lock.Lock()
+	defer fileWatcher.lock.Unlock()
+	info, err := os.Stat(fileWatcher.path)
+	if os.IsNotExist(err) {
+		return
+	}
+	if err != nil {
+		fileWatcher.err = err
+		return
+	}
+	if last := fileWatcher.info; last != nil && os.SameFile(last, info) && info.Size() == last.Size() && info.ModTime().Equal(last.ModTime()) {
+		return
+	}
+	store, err := LoadFile(fileWatcher.path)
+	if err != nil {
+		// The file may be part way through being replaced; try again next
+		// time.
+		fileWatcher.err = err
+		return
+	}
+	fileWatcher.syncStore.Absorb(store)
+	fileWatcher.info, fileWatcher.err = info, nil
+}
+
+// Err returns the error from the most recent check, if it failed.
+func (fileWatcher *FileWatcher) Err() error {
+	fileWatcher.lock.Lock()
+	defer fileWatcher.lock.Unlock()
+	return fileWatcher.err
+}
+
+// Close is the same as Stop, but returns the error from the most recent
+// check; it lets a FileWatcher be used as an io.Closer.
+func (fileWatcher *FileWatcher) Close() error {
+	fileWatcher.Stop()
+	return fileWatcher.Err()
+}
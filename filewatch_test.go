@@ -0,0 +1,76 @@
+package kvt_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func TestWatchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	syncStore := kvt.NewSyncStore(nil)
+	fileWatcher := syncStore.WatchFile(path, time.Millisecond)
+	defer fileWatcher.Close()
+
+	// Another process saves to the file.
+	other := kvt.Store{}
+	other.SetTimestamped("A", "one", 1)
+	if err := other.MergeSaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	waitFor := func(key string, value string) {
+		t.Helper()
+		for deadline := time.Now().Add(5 * time.Second); syncStore.Get(key) != value; {
+			if time.Now().After(deadline) {
+				t.Fatal(syncStore, fileWatcher.Err())
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}
+	waitFor("A", "one")
+	other.SetTimestamped("B", "two", 2)
+	if err := other.MergeSaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	waitFor("B", "two")
+}
+
+func TestWatchFileSameSizeAndTime(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "store.json")
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	if err := store.SaveFile(path); err != nil {
+		t.Fatal(err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncStore := kvt.NewSyncStore(nil)
+	fileWatcher := syncStore.WatchFile(path, time.Millisecond)
+	defer fileWatcher.Close()
+
+	// The file is atomically replaced by one of the same size and
+	// modification time.
+	store.SetTimestamped("A", "two", 2)
+	replacement := filepath.Join(dir, "replacement.json")
+	if err = store.SaveFile(replacement); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Chtimes(replacement, info.ModTime(), info.ModTime()); err != nil {
+		t.Fatal(err)
+	}
+	if err = os.Rename(replacement, path); err != nil {
+		t.Fatal(err)
+	}
+	for deadline := time.Now().Add(5 * time.Second); syncStore.Get("A") != "two"; {
+		if time.Now().After(deadline) {
+			t.Fatal(syncStore, fileWatcher.Err())
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -0,0 +1,43 @@
+package kvt
+
+import (
+	"fmt"
+	"strings"
+)
+
+// StoreFlag is a flag.Value that sets an entry in the Store it converts for
+// each key=value given, so a repeatable flag can seed a store from the
+// command line:
+//
+//	store := kvt.Store{}
+//	flag.Var(kvt.StoreFlag(store), "set", "set a `key=value` in the store")
+//
+// Entries are set as Set would, though each is given a timestamp newer than
+// any the key already has, so when a key is given more than once, the last
+// value wins. A key already at MaxTimestamp can't be given a newer one, so
+// setting it again is an error. The Store must not be nil.
+type StoreFlag Store
+
+// String returns the store's entries as SimpleString does.
+func (storeFlag StoreFlag) String() string {
+	return Store(storeFlag).SimpleString()
+}
+
+// Set sets the entry given as key=value; the value may contain more equal
+// signs, but the key may not.
+func (storeFlag StoreFlag) Set(s string) error {
+	i := strings.IndexByte(s, '=')
+	if i < 0 {
+		return fmt.Errorf("expected key=value, not %q", s)
+	}
+	store := Store(storeFlag)
+	timestamp := Now()
+	if valueTimestamp := store[s[:i]]; valueTimestamp != nil && valueTimestamp.Timestamp >= timestamp {
+		if valueTimestamp.Timestamp == MaxTimestamp {
+			return fmt.Errorf("key %q already has the newest possible timestamp", s[:i])
+		}
+		timestamp = valueTimestamp.Timestamp + 1
+	}
+	store.SetTimestamped(s[:i], s[i+1:], timestamp)
+	return nil
+}
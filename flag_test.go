@@ -0,0 +1,18 @@
+package kvt_test
+
+import (
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestStoreFlagMaxTimestamp(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", kvt.MaxTimestamp)
+	if err := kvt.StoreFlag(store).Set("A=two"); err == nil || err.Error() != `key "A" already has the newest possible timestamp` {
+		t.Fatal(err)
+	}
+	if s := store.String(); s != `{"A":["one",9223372036854775807]}` {
+		t.Fatal(s)
+	}
+}
@@ -0,0 +1,23 @@
+package kvt_test
+
+import (
+	"flag"
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStoreFlag() {
+	store := kvt.Store{}
+	flagSet := flag.NewFlagSet("seed", flag.ContinueOnError)
+	flagSet.Var(kvt.StoreFlag(store), "set", "set a `key=value` in the store")
+	err := flagSet.Parse([]string{"-set", "region=us-east", "-set", "query=a=b", "-set", "region=us-west"})
+	fmt.Println(store.SimpleString(), err)
+
+	err = flagSet.Parse([]string{"-set", "missing"})
+	fmt.Println(err)
+
+	// Output:
+	// query=a=b,region=us-west <nil>
+	// invalid value "missing" for flag -set: expected key=value, not "missing"
+}
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package kvt
+
+// lockFile is a no-op on platforms without flock; saves are still merged but
+// concurrent savers may race.
+func lockFile(path string) (func() error, error) {
+	return func() error { return nil }, nil
+}
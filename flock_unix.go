@@ -0,0 +1,28 @@
+//go:build unix
+
+package kvt
+
+import (
+	"os"
+	"syscall"
+)
+
+// lockFile obtains an exclusive advisory lock on the file at path, creating
+// it if needed, and returns a function to release the lock.
+func lockFile(path string) (func() error, error) {
+	f, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0666)
+	if err != nil {
+		return nil, err
+	}
+	if err = syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		f.Close()
+		return nil, err
+	}
+	return func() error {
+		err := syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		if err2 := f.Close(); err == nil {
+			err = err2
+		}
+		return err
+	}, nil
+}
@@ -0,0 +1,82 @@
+package kvt
+
+import "strings"
+
+// FoldStore is a case-insensitive view of a Store. Keys are canonicalized with
+// Fold before being used with the Store, and the casing each key was last set
+// or deleted with is kept in Display for showing to people.
+//
+// Display is local to the FoldStore; stores merged with Absorb only share the
+// canonical keys.
+type FoldStore struct {
+	Store Store
+	// Fold canonicalizes keys; strings.ToLower is used if nil.
+	Fold func(string) string
+	// Display maps canonical keys to their display casing.
+	Display map[string]string
+}
+
+// NewFoldStore returns a FoldStore for the store given, which should only hold
+// canonical keys already. If store is nil, a new empty Store is used.
+func NewFoldStore(store Store) *FoldStore {
+	if store == nil {
+		store = Store{}
+	}
+	return &FoldStore{Store: store, Display: map[string]string{}}
+}
+
+// Get returns the value for any casing of the key; see Store.Get.
+func (foldStore *FoldStore) Get(key string) string {
+	return foldStore.Store.Get(foldStore.fold(key))
+}
+
+// Set is equivalent to SetTimestamped(key, value, Now()).
+func (foldStore *FoldStore) Set(key string, value string) {
+	foldStore.Store.Set(foldStore.canonical(key), value)
+}
+
+// SetTimestamped stores the value under the canonical form of the key; see
+// Store.SetTimestamped.
+func (foldStore *FoldStore) SetTimestamped(key string, value string, timestamp int64) {
+	foldStore.Store.SetTimestamped(foldStore.canonical(key), value, timestamp)
+}
+
+// Delete is equivalent to DeleteTimestamped(key, Now()).
+func (foldStore *FoldStore) Delete(key string) {
+	foldStore.Store.Delete(foldStore.canonical(key))
+}
+
+// DeleteTimestamped records a deletion marker for the canonical form of the
+// key; see Store.DeleteTimestamped.
+func (foldStore *FoldStore) DeleteTimestamped(key string, timestamp int64) {
+	foldStore.Store.DeleteTimestamped(foldStore.canonical(key), timestamp)
+}
+
+// DisplayKey returns the casing any casing of the key was last set or deleted
+// with; if unknown the canonical key is returned.
+func (foldStore *FoldStore) DisplayKey(key string) string {
+	canonicalKey := foldStore.fold(key)
+	if displayKey, ok := foldStore.Display[canonicalKey]; ok {
+		return displayKey
+	}
+	return canonicalKey
+}
+
+// fold returns the canonical form of the key.
+func (foldStore *FoldStore) fold(key string) string {
+	if foldStore.Fold == nil {
+		return strings.ToLower(key)
+	}
+	return foldStore.Fold(key)
+}
+
+// canonical returns the canonical form of the key, remembering the key as the
+// display casing.
+func (foldStore *FoldStore) canonical(key string) string {
+	canonicalKey := foldStore.fold(key)
+	if foldStore.Display == nil {
+		foldStore.Display = map[string]string{}
+	}
+	foldStore.Display[canonicalKey] = key
+	return canonicalKey
+}
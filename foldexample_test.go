@@ -0,0 +1,35 @@
+package kvt_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleFoldStore() {
+	foldStore := kvt.NewFoldStore(nil)
+	foldStore.SetTimestamped(`HKLM\Software\Vendor`, "one", 1)
+	fmt.Println(foldStore.Get(`hklm\software\vendor`))
+	foldStore.SetTimestamped(`hklm\SOFTWARE\Vendor`, "two", 2)
+	fmt.Println(foldStore.Get(`HKLM\Software\Vendor`))
+	fmt.Println(foldStore.DisplayKey(`HKLM\Software\Vendor`))
+	fmt.Println(foldStore.Store)
+
+	// Output:
+	// one
+	// two
+	// hklm\SOFTWARE\Vendor
+	// {"hklm\\software\\vendor":["two",2]}
+}
+
+func ExampleFoldStore_Fold() {
+	foldStore := kvt.NewFoldStore(kvt.Store{})
+	foldStore.Fold = strings.ToUpper
+	foldStore.SetTimestamped("Path", "/bin", 1)
+	foldStore.DeleteTimestamped("PATH", 2)
+	fmt.Println(foldStore.Store, foldStore.DisplayKey("path"))
+
+	// Output:
+	// {"PATH":[null,2]} PATH
+}
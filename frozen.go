@@ -0,0 +1,102 @@
+package kvt
+
+import (
+	"encoding/json"
+	"errors"
+)
+
+// ErrFrozen is the value FrozenStore's mutating methods panic with.
+var ErrFrozen = errors.New("kvt: store is frozen")
+
+// FrozenStore is a read-only Store, for handing to code that must not be able
+// to modify it. It implements Storer so it can be passed wherever a Storer is
+// read, but its mutating methods panic with ErrFrozen.
+type FrozenStore struct {
+	store Store
+}
+
+// Freeze returns a FrozenStore holding a copy of the store's entries; later
+// changes to the store are not seen by the FrozenStore.
+func (store Store) Freeze() *FrozenStore {
+	return &FrozenStore{store: store.Copy()}
+}
+
+// Freeze returns a FrozenStore of the current contents; it is O(1) as it uses
+// a Snapshot, leaving the copy to the next write.
+func (syncStore *SyncStore) Freeze() *FrozenStore {
+	return &FrozenStore{store: syncStore.Snapshot()}
+}
+
+// Get returns the value for a key; see Store.Get.
+func (frozenStore *FrozenStore) Get(key string) string {
+	return frozenStore.store.Get(key)
+}
+
+// Set panics with ErrFrozen.
+func (frozenStore *FrozenStore) Set(key string, value string) {
+	panic(ErrFrozen)
+}
+
+// SetTimestamped panics with ErrFrozen.
+func (frozenStore *FrozenStore) SetTimestamped(key string, value string, timestamp int64) {
+	panic(ErrFrozen)
+}
+
+// Delete panics with ErrFrozen.
+func (frozenStore *FrozenStore) Delete(key string) {
+	panic(ErrFrozen)
+}
+
+// DeleteTimestamped panics with ErrFrozen.
+func (frozenStore *FrozenStore) DeleteTimestamped(key string, timestamp int64) {
+	panic(ErrFrozen)
+}
+
+// Purge panics with ErrFrozen.
+func (frozenStore *FrozenStore) Purge(cutoff int64) {
+	panic(ErrFrozen)
+}
+
+// Absorb panics with ErrFrozen.
+func (frozenStore *FrozenStore) Absorb(store2 Store) {
+	panic(ErrFrozen)
+}
+
+// ModifiedSince returns a new Store of the entries newer than the timestamp;
+// see Store.ModifiedSince.
+func (frozenStore *FrozenStore) ModifiedSince(timestamp int64) Store {
+	return frozenStore.store.ModifiedSince(timestamp)
+}
+
+// Copy returns a new, modifiable Store holding copies of all the entries; see
+// Store.Copy.
+func (frozenStore *FrozenStore) Copy() Store {
+	return frozenStore.store.Copy()
+}
+
+// Hash returns a hash of the keys, timestamps, and flags; see Store.Hash.
+func (frozenStore *FrozenStore) Hash() string {
+	return frozenStore.store.Hash()
+}
+
+// PrefixKeys returns the sorted keys with values that start with prefix; see
+// Store.PrefixKeys.
+func (frozenStore *FrozenStore) PrefixKeys(prefix string) []string {
+	return frozenStore.store.PrefixKeys(prefix)
+}
+
+// ToMap returns the live entries as a plain map; see Store.ToMap.
+func (frozenStore *FrozenStore) ToMap() map[string]string {
+	return frozenStore.store.ToMap()
+}
+
+// MarshalJSON returns the JSON encoded version of the store or an error.
+func (frozenStore *FrozenStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(frozenStore.store)
+}
+
+// String returns the JSON encoded string representation of the store; see
+// Store.String.
+func (frozenStore *FrozenStore) String() string {
+	return frozenStore.store.String()
+}
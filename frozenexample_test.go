@@ -0,0 +1,27 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_Freeze() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	frozenStore := store.Freeze()
+
+	// Later changes to the store don't show through.
+	store.SetTimestamped("A", "uno", 2)
+	fmt.Println(frozenStore.Get("A"), store.Get("A"))
+
+	// Attempts to change the frozen store panic.
+	func() {
+		defer func() { fmt.Println(recover()) }()
+		frozenStore.Set("B", "two")
+	}()
+
+	// Output:
+	// one uno
+	// kvt: store is frozen
+}
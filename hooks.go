@@ -0,0 +1,104 @@
+package kvt
+
+// Hook is a function called by a SyncStore after an entry changes, with
+// copies of the entry before and after the change; oldValueTimestamp is nil
+// if the key had no entry.
+type Hook func(key string, oldValueTimestamp *ValueTimestamp, newValueTimestamp *ValueTimestamp)
+
+type hooks struct {
+	onSet    []Hook
+	onDelete []Hook
+	onAbsorb []Hook
+	// listeners are registered with listen and removed when done.
+	listeners []*listener
+}
+
+// listener receives a SyncStore's changes on behalf of a WAL, AutoSaver, or
+// the like; see SyncStore.listen.
+type listener struct {
+	// changed, if set, is called once per write with all of its changes.
+	changed func(changes []Change)
+	// purged, if set, is called after each Purge with its cutoff, as purged
+	// deletion markers are not reported as changes.
+	purged func(cutoff int64)
+}
+
+// empty returns true if no hooks are registered.
+func (hooks *hooks) empty() bool {
+	return len(hooks.onSet) == 0 && len(hooks.onDelete) == 0 && len(hooks.onAbsorb) == 0 && len(hooks.listeners) == 0
+}
+
+// call calls the registered hooks for each of the changes.
+func (hooks *hooks) call(changes []Change) {
+	for _, change := range changes {
+		var fns []Hook
+		switch {
+		case change.Absorbed:
+			fns = hooks.onAbsorb
+		case change.New.Value == nil:
+			fns = hooks.onDelete
+		default:
+			fns = hooks.onSet
+		}
+		for _, fn := range fns {
+			fn(change.Key, change.Old, change.New)
+		}
+	}
+	if len(changes) > 0 {
+		for _, listener := range hooks.listeners {
+			if listener.changed != nil {
+				listener.changed(changes)
+			}
+		}
+	}
+}
+
+// listen registers the listener and returns a function that unregisters it,
+// which is safe to call more than once. Once it returns, the listener is not
+// called for any later write, though a call already under way may still be
+// in progress.
+func (syncStore *SyncStore) listen(l *listener) func() {
+	syncStore.lock.Lock()
+	syncStore.hooks.listeners = append(syncStore.hooks.listeners, l)
+	syncStore.lock.Unlock()
+	return func() {
+		syncStore.lock.Lock()
+		defer syncStore.lock.Unlock()
+		// A new slice, as change may be calling a copy of the old one.
+		listeners := make([]*listener, 0, len(syncStore.hooks.listeners))
+		for _, l2 := range syncStore.hooks.listeners {
+			if l2 != l {
+				listeners = append(listeners, l2)
+			}
+		}
+		syncStore.hooks.listeners = listeners
+	}
+}
+
+// OnSet registers a hook to be called whenever a local write, such as Set,
+// gives a key a new value or timestamp.
+//
+// Hooks are called synchronously, in the goroutine that made the change,
+// after the write has completed and the lock has been released; so they may
+// use the SyncStore themselves, but they delay the caller's return.
+func (syncStore *SyncStore) OnSet(hook Hook) {
+	syncStore.lock.Lock()
+	syncStore.hooks.onSet = append(syncStore.hooks.onSet, hook)
+	syncStore.lock.Unlock()
+}
+
+// OnDelete registers a hook to be called whenever a local write, such as
+// Delete, records a deletion marker for a key; see OnSet.
+func (syncStore *SyncStore) OnDelete(hook Hook) {
+	syncStore.lock.Lock()
+	syncStore.hooks.onDelete = append(syncStore.hooks.onDelete, hook)
+	syncStore.lock.Unlock()
+}
+
+// OnAbsorbApplied registers a hook to be called whenever an Absorb, or a
+// merge with a file's contents, updates an entry; see OnSet.
+func (syncStore *SyncStore) OnAbsorbApplied(hook Hook) {
+	syncStore.lock.Lock()
+	syncStore.hooks.onAbsorb = append(syncStore.hooks.onAbsorb, hook)
+	syncStore.lock.Unlock()
+}
@@ -0,0 +1,33 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore_OnSet() {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.OnSet(func(key string, oldValueTimestamp, newValueTimestamp *kvt.ValueTimestamp) {
+		fmt.Println("set", key, oldValueTimestamp, newValueTimestamp)
+	})
+	syncStore.OnDelete(func(key string, oldValueTimestamp, newValueTimestamp *kvt.ValueTimestamp) {
+		fmt.Println("delete", key, oldValueTimestamp, newValueTimestamp)
+	})
+	syncStore.OnAbsorbApplied(func(key string, oldValueTimestamp, newValueTimestamp *kvt.ValueTimestamp) {
+		fmt.Println("absorb", key, oldValueTimestamp, newValueTimestamp)
+	})
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.SetTimestamped("A", "old", 0) // Discarded; no hook called.
+	syncStore.DeleteTimestamped("A", 2)
+	remote := kvt.Store{}
+	remote.SetTimestamped("A", "two", 3)
+	remote.SetTimestamped("B", "three", 3)
+	syncStore.Absorb(remote)
+
+	// Output:
+	// set A <nil> one,1
+	// delete A one,1 nil,2
+	// absorb A nil,2 two,3
+	// absorb B <nil> three,3
+}
@@ -0,0 +1,127 @@
+package kvt
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+)
+
+// MarshalJSONObjects returns the JSON encoded store with each entry as an
+// object rather than a positional tuple, {"value":"one","ts":123}, plus
+// "flags" when any Flags are set; deletion markers have a null value. This is
+// easier for people, and tools such as jq, to read. The encoding is canonical
+// in the same way as MarshalJSON's, and UnmarshalJSON reads either format.
+func (store Store) MarshalJSONObjects() ([]byte, error) {
+	if store == nil {
+		return []byte("null"), nil
+	}
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, key := range store.sortedKeys() {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(k)
+		buf.WriteByte(':')
+		valueTimestamp := store[key]
+		if valueTimestamp == nil {
+			buf.WriteString("null")
+			continue
+		}
+		v, err := valueTimestamp.MarshalJSONObject()
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(v)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+// MarshalJSONObject returns the JSON encoded valueTimestamp in the object
+// format described by Store.MarshalJSONObjects.
+func (valueTimestamp *ValueTimestamp) MarshalJSONObject() ([]byte, error) {
+	v := []byte("null")
+	if valueTimestamp.Value != nil {
+		var err error
+		if v, err = json.Marshal(*valueTimestamp.Value); err != nil {
+			return nil, err
+		}
+	}
+	b := append([]byte(`{"value":`), v...)
+	b = append(b, `,"ts":`...)
+	b = strconv.AppendInt(b, valueTimestamp.Timestamp, 10)
+	if valueTimestamp.Flags != 0 {
+		b = append(b, `,"flags":`...)
+		b = strconv.AppendUint(b, uint64(valueTimestamp.Flags), 10)
+	}
+	return append(b, '}'), nil
+}
+
+// unmarshalJSONObject loads valueTimestamp from the JSON encoded b in the
+// object format or returns an error.
+func (valueTimestamp *ValueTimestamp) unmarshalJSONObject(b []byte) error {
+	var object map[string]json.RawMessage
+	if err := json.Unmarshal(b, &object); err != nil {
+		return fmt.Errorf("invalid entry object from: %s: %s", b, err)
+	}
+	for field := range object {
+		if field != "value" && field != "ts" && field != "flags" {
+			return fmt.Errorf("invalid entry object from: %s: unknown field %q", b, field)
+		}
+	}
+	if object["value"] == nil || object["ts"] == nil {
+		return fmt.Errorf(`expected {"value":value,"ts":timestamp} from: %s`, b)
+	}
+	value, ok := rawJSONString(object["value"])
+	if !ok {
+		return fmt.Errorf("invalid value from: %s", b)
+	}
+	valueTimestamp.Value = value
+	t, ok := rawJSONInt(object["ts"])
+	if !ok {
+		return fmt.Errorf("invalid timestamp from: %s", b)
+	}
+	valueTimestamp.Timestamp = t
+	valueTimestamp.Flags = 0
+	if object["flags"] != nil {
+		f, ok := rawJSONInt(object["flags"])
+		if !ok || f < 0 || f > math.MaxUint32 {
+			return fmt.Errorf("invalid flags from: %s", b)
+		}
+		valueTimestamp.Flags = Flags(f)
+	}
+	return nil
+}
+
+// rawJSONInt returns the integer encoded in raw, which must be a single valid
+// JSON value, such as one decoded into a json.RawMessage; see jsonInt.
+func rawJSONInt(raw json.RawMessage) (int64, bool) {
+	// A valid JSON value starting with a minus sign or digit is a number.
+	if len(raw) == 0 || (raw[0] != '-' && (raw[0] < '0' || raw[0] > '9')) {
+		return 0, false
+	}
+	return jsonInt(json.Number(raw))
+}
+
+// rawJSONString returns the string encoded in raw, or nil if raw is null, and
+// whether raw was either.
+func rawJSONString(raw json.RawMessage) (*string, bool) {
+	if string(raw) == "null" {
+		return nil, true
+	}
+	if len(raw) == 0 || raw[0] != '"' {
+		return nil, false
+	}
+	var value string
+	if err := json.Unmarshal(raw, &value); err != nil {
+		return nil, false
+	}
+	return &value, true
+}
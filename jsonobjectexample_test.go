@@ -0,0 +1,28 @@
+package kvt_test
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_MarshalJSONObjects() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	store.SetTimestamped("C", "three", 3)
+	store["C"].Flags = kvt.FlagPinned
+	b, err := store.MarshalJSONObjects()
+	fmt.Println(string(b), err)
+
+	// Either format is read back.
+	store2 := kvt.Store{}
+	fmt.Println(json.Unmarshal(b, &store2))
+	fmt.Println(store2)
+
+	// Output:
+	// {"A":{"value":"one","ts":1},"B":{"value":null,"ts":2},"C":{"value":"three","ts":3,"flags":4}} <nil>
+	// <nil>
+	// {"A":["one",1],"B":[null,2],"C":["three",3,4]}
+}
@@ -0,0 +1,101 @@
+package kvt
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// WriteJSON writes the store to w in the canonical JSON encoding given by
+// MarshalJSON, but encodes and writes one entry at a time rather than
+// building the whole document in memory first.
+func (store Store) WriteJSON(w io.Writer) error {
+	if store == nil {
+		_, err := io.WriteString(w, "null")
+		return err
+	}
+	writer := bufio.NewWriter(w)
+	writer.WriteByte('{')
+	for i, key := range store.sortedKeys() {
+		if i > 0 {
+			writer.WriteByte(',')
+		}
+		k, err := json.Marshal(key)
+		if err != nil {
+			return err
+		}
+		v := []byte("null")
+		if valueTimestamp := store[key]; valueTimestamp != nil {
+			if v, err = valueTimestamp.MarshalJSON(); err != nil {
+				return err
+			}
+		}
+		writer.Write(k)
+		writer.WriteByte(':')
+		if _, err = writer.Write(v); err != nil {
+			return err
+		}
+	}
+	writer.WriteByte('}')
+	return writer.Flush()
+}
+
+// ReadJSON reads a JSON encoded store from r, absorbing each entry into the
+// store as it is decoded, so no intermediate Store of the whole document is
+// needed. Entries are validated as by UnmarshalJSON. On error, the entries
+// decoded before the problem will already have been absorbed. Anything in r
+// after the JSON object is left unread, though it may have been buffered.
+func (store Store) ReadJSON(r io.Reader) error {
+	return decodeJSONEntries(json.NewDecoder(r), func(key string, valueTimestamp *ValueTimestamp) error {
+		store.absorbEntry(key, valueTimestamp)
+		return nil
+	})
+}
+
+// decodeJSONEntries decodes a JSON encoded store from decoder, calling fn
+// with each entry in turn. Entries that are null or invalid give an error
+// naming their key.
+func decodeJSONEntries(decoder *json.Decoder, fn func(key string, valueTimestamp *ValueTimestamp) error) error {
+	if err := expectJSONDelim(decoder, '{'); err != nil {
+		return err
+	}
+	for decoder.More() {
+		token, err := decoder.Token()
+		if err != nil {
+			return err
+		}
+		key, ok := token.(string)
+		if !ok {
+			return fmt.Errorf("kvt: expected JSON key, not %v", token)
+		}
+		var raw json.RawMessage
+		if err = decoder.Decode(&raw); err != nil {
+			return err
+		}
+		if string(raw) == "null" {
+			return fmt.Errorf("kvt: null entry for key %q", key)
+		}
+		valueTimestamp := &ValueTimestamp{}
+		if err = valueTimestamp.UnmarshalJSON(raw); err != nil {
+			return fmt.Errorf("kvt: invalid entry for key %q: %s", key, err)
+		}
+		if err = fn(key, valueTimestamp); err != nil {
+			return err
+		}
+	}
+	return expectJSONDelim(decoder, '}')
+}
+
+// expectJSONDelim reads the next token from decoder, returning an error if it
+// isn't the delimiter given.
+func expectJSONDelim(decoder *json.Decoder, delim json.Delim) error {
+	token, err := decoder.Token()
+	if err != nil {
+		return err
+	}
+	if token != delim {
+		return fmt.Errorf("kvt: expected JSON %q, not %v", delim, token)
+	}
+	return nil
+}
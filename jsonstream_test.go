@@ -0,0 +1,45 @@
+package kvt_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestWriteJSONMatchesMarshal(t *testing.T) {
+	for _, store := range []kvt.Store{{}, {"": {Value: new(string)}}} {
+		var buf bytes.Buffer
+		if err := store.WriteJSON(&buf); err != nil {
+			t.Fatal(err)
+		}
+		b, err := json.Marshal(store)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if buf.String() != string(b) {
+			t.Fatal(buf.String(), string(b))
+		}
+	}
+	store := kvt.Store{}
+	store.SetTimestamped("a&b", "x\"y\n ", -3)
+	store.DeleteTimestamped("z", 9)
+	store["z"].Flags = kvt.FlagPinned
+	var buf bytes.Buffer
+	if err := store.WriteJSON(&buf); err != nil {
+		t.Fatal(err)
+	}
+	if b, _ := json.Marshal(store); buf.String() != string(b) {
+		t.Fatal(buf.String(), string(b))
+	}
+}
+
+func TestReadJSONJunk(t *testing.T) {
+	for _, junk := range []string{"", "[]", "null", `{"A"}`, `{"A":1}`, `{"A":["one",1]`, `{"A":["one",1],}`} {
+		if err := (kvt.Store{}).ReadJSON(strings.NewReader(junk)); err == nil {
+			t.Errorf("expected error from %q", junk)
+		}
+	}
+}
@@ -0,0 +1,41 @@
+package kvt_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_WriteJSON() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	store.SetTimestamped("C", "three", 3)
+	store["C"].Flags = kvt.FlagPinned
+	fmt.Println(store.WriteJSON(os.Stdout))
+
+	// Output:
+	// {"A":["one",1],"B":[null,2],"C":["three",3,4]}<nil>
+}
+
+func ExampleStore_ReadJSON() {
+	// Entries are absorbed as they are read, so older ones don't replace
+	// newer ones already in the store.
+	store := kvt.Store{}
+	store.SetTimestamped("A", "newer", 5)
+	err := store.ReadJSON(strings.NewReader(`{"A":["older",1],"B":[null,2],"C":["three",3]}`))
+	fmt.Println(store, err)
+
+	// A round trip through WriteJSON.
+	var buf bytes.Buffer
+	store.WriteJSON(&buf)
+	store2 := kvt.Store{}
+	fmt.Println(store2.ReadJSON(&buf), store2.Hash() == store.Hash())
+
+	// Output:
+	// {"A":["newer",5],"B":[null,2],"C":["three",3]} <nil>
+	// <nil> true
+}
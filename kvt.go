@@ -11,16 +11,34 @@
 package kvt
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"hash/fnv"
+	"io"
+	"math"
+	"runtime"
 	"sort"
-	"time"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode/utf8"
 )
 
 // Store is a Key|Value|Timestamp simple store.
 type Store map[string]*ValueTimestamp
 
+// FromMap returns a new store holding the keys and values from m, all with the
+// timestamp given.
+func FromMap(m map[string]string, timestamp int64) Store {
+	store := make(Store, len(m))
+	for key, value := range m {
+		store.SetTimestamped(key, value, timestamp)
+	}
+	return store
+}
+
 // Get returns the value for a key; if the key does not exist or is marked
 // deleted, an empty string is returned.
 func (store Store) Get(key string) string {
@@ -31,9 +49,9 @@ func (store Store) Get(key string) string {
 	return *valueTimestamp.Value
 }
 
-// Set is equivalent to SetTimestamped(key, value, time.Now().UnixNano()).
+// Set is equivalent to SetTimestamped(key, value, Now()).
 func (store Store) Set(key string, value string) {
-	store.SetTimestamped(key, value, time.Now().UnixNano())
+	store.SetTimestamped(key, value, Now())
 }
 
 // SetTimestamped stores the value for the key as long as there isn't already a
@@ -41,16 +59,17 @@ func (store Store) Set(key string, value string) {
 func (store Store) SetTimestamped(key string, value string, timestamp int64) {
 	valueTimestamp := store[key]
 	if valueTimestamp == nil {
-		store[key] = &ValueTimestamp{&value, timestamp}
+		store[key] = &ValueTimestamp{Value: &value, Timestamp: timestamp}
 	} else if valueTimestamp.Timestamp < timestamp {
 		valueTimestamp.Value = &value
 		valueTimestamp.Timestamp = timestamp
+		valueTimestamp.Flags = 0
 	}
 }
 
-// Delete is equivalent to DeleteTimestamped(key, time.Now().UnixNano()).
+// Delete is equivalent to DeleteTimestamped(key, Now()).
 func (store Store) Delete(key string) {
-	store.DeleteTimestamped(key, time.Now().UnixNano())
+	store.DeleteTimestamped(key, Now())
 }
 
 // DeleteTimestamped records a deletion marker for the key as long as there
@@ -58,51 +77,559 @@ func (store Store) Delete(key string) {
 func (store Store) DeleteTimestamped(key string, timestamp int64) {
 	valueTimestamp := store[key]
 	if valueTimestamp == nil {
-		store[key] = &ValueTimestamp{nil, timestamp}
+		store[key] = &ValueTimestamp{Timestamp: timestamp}
 	} else if valueTimestamp.Timestamp < timestamp {
 		valueTimestamp.Value = nil
 		valueTimestamp.Timestamp = timestamp
+		valueTimestamp.Flags = 0
+	}
+}
+
+// GetOrSet returns the existing value for the key if it has one, with loaded
+// true. Otherwise it does a Set with the value given and returns that value,
+// with loaded false; mirroring sync.Map.LoadOrStore. If the key has a deletion
+// marker newer than Now(), the Set would do nothing, so "" is returned, as
+// Get would, with loaded true.
+func (store Store) GetOrSet(key string, value string) (actual string, loaded bool) {
+	valueTimestamp := store[key]
+	if valueTimestamp != nil && valueTimestamp.Value != nil {
+		return *valueTimestamp.Value, true
+	}
+	timestamp := Now()
+	if valueTimestamp != nil && valueTimestamp.Timestamp >= timestamp {
+		return "", true
+	}
+	store.SetTimestamped(key, value, timestamp)
+	return value, false
+}
+
+// Swap is like Set but returns the value the key had beforehand and whether
+// it had one at all, mirroring sync.Map.Swap. Unlike sync.Map.Swap, the value
+// isn't stored if the key's entry is newer than Now(); swapped reports
+// whether it was.
+func (store Store) Swap(key string, value string) (previous string, loaded bool, swapped bool) {
+	valueTimestamp := store[key]
+	if valueTimestamp != nil && valueTimestamp.Value != nil {
+		previous, loaded = *valueTimestamp.Value, true
+	}
+	timestamp := Now()
+	if valueTimestamp != nil && valueTimestamp.Timestamp >= timestamp {
+		return previous, loaded, false
+	}
+	store.SetTimestamped(key, value, timestamp)
+	return previous, loaded, true
+}
+
+// LoadAndDelete is like Delete but returns the value the key had beforehand
+// and whether it had one at all, mirroring sync.Map.LoadAndDelete. Unlike
+// sync.Map.LoadAndDelete, nothing is deleted if the key's entry is newer than
+// Now(); deleted reports whether a deletion marker was recorded.
+func (store Store) LoadAndDelete(key string) (value string, loaded bool, deleted bool) {
+	valueTimestamp := store[key]
+	if valueTimestamp != nil && valueTimestamp.Value != nil {
+		value, loaded = *valueTimestamp.Value, true
+	}
+	timestamp := Now()
+	if valueTimestamp != nil && valueTimestamp.Timestamp >= timestamp {
+		return value, loaded, false
+	}
+	store.DeleteTimestamped(key, timestamp)
+	return value, loaded, true
+}
+
+// Touch is equivalent to TouchTimestamped(key, Now()).
+func (store Store) Touch(key string) bool {
+	return store.TouchTimestamped(key, Now())
+}
+
+// TouchTimestamped updates just the timestamp of the key's value, so it will
+// win merges against older entries without changing the value itself. Nothing
+// is changed, and false is returned, if the key has no value or its timestamp
+// is already newer or equal.
+func (store Store) TouchTimestamped(key string, timestamp int64) bool {
+	valueTimestamp := store[key]
+	if valueTimestamp == nil || valueTimestamp.Value == nil || valueTimestamp.Timestamp >= timestamp {
+		return false
+	}
+	valueTimestamp.Timestamp = timestamp
+	return true
+}
+
+// Rename is equivalent to RenameTimestamped(oldKey, newKey, Now()).
+func (store Store) Rename(oldKey string, newKey string) bool {
+	return store.RenameTimestamped(oldKey, newKey, Now())
+}
+
+// RenameTimestamped moves the value of oldKey to newKey, recording a deletion
+// marker for oldKey, with both using the same timestamp so that stores
+// absorbing the change see either both sides or neither. Nothing is changed,
+// and false is returned, if oldKey has no value or either key already has an
+// entry with a newer or equal timestamp.
+func (store Store) RenameTimestamped(oldKey string, newKey string, timestamp int64) bool {
+	oldValueTimestamp := store[oldKey]
+	if oldKey == newKey || oldValueTimestamp == nil || oldValueTimestamp.Value == nil || oldValueTimestamp.Timestamp >= timestamp {
+		return false
+	}
+	if newValueTimestamp := store[newKey]; newValueTimestamp != nil && newValueTimestamp.Timestamp >= timestamp {
+		return false
+	}
+	value, flags := *oldValueTimestamp.Value, oldValueTimestamp.Flags
+	store.DeleteTimestamped(oldKey, timestamp)
+	store.SetTimestamped(newKey, value, timestamp)
+	store[newKey].Flags = flags
+	return true
+}
+
+// TransformValues replaces each value in the store with what fn returns for
+// it, keeping the existing timestamps; deletion markers are left alone. Since
+// the timestamps don't change, other stores won't pick up the new values
+// through Absorb, so this is meant for local migrations such as re-encoding
+// values to a new format on every store.
+func (store Store) TransformValues(fn func(key string, value string) string) {
+	for key, valueTimestamp := range store {
+		if valueTimestamp.Value != nil {
+			value := fn(key, *valueTimestamp.Value)
+			valueTimestamp.Value = &value
+		}
+	}
+}
+
+// Clear is equivalent to ClearTimestamped(Now()).
+func (store Store) Clear() {
+	store.ClearTimestamped(Now())
+}
+
+// ClearTimestamped records deletion markers for every key that currently has
+// a value, as long as that value's timestamp is older than the timestamp
+// given. Unlike simply discarding the entries, the deletion markers will
+// propagate through Absorb to other stores.
+func (store Store) ClearTimestamped(timestamp int64) {
+	for key, valueTimestamp := range store {
+		if valueTimestamp.Value != nil {
+			store.DeleteTimestamped(key, timestamp)
+		}
 	}
 }
 
-// Purge discards any deletion markers older than the cutoff timestamp given.
+// Purge discards any deletion markers older than the cutoff timestamp given,
+// other than those with FlagPinned set.
 func (store Store) Purge(cutoff int64) {
 	for key, valueTimestamp := range store {
-		if valueTimestamp.Value == nil && valueTimestamp.Timestamp < cutoff {
+		if valueTimestamp.purgeable(cutoff) {
 			delete(store, key)
 		}
 	}
 }
 
+// purgeable returns whether Purge with the cutoff would discard the entry.
+func (valueTimestamp *ValueTimestamp) purgeable(cutoff int64) bool {
+	return valueTimestamp.Value == nil && valueTimestamp.Timestamp < cutoff && valueTimestamp.Flags&FlagPinned == 0
+}
+
+// Copy returns a new store holding copies of all the entries in store.
+func (store Store) Copy() Store {
+	store2 := make(Store, len(store))
+	for key, valueTimestamp := range store {
+		valueTimestampCopy := *valueTimestamp
+		store2[key] = &valueTimestampCopy
+	}
+	return store2
+}
+
+// Filter returns a new store holding copies of just the entries, including
+// deletion markers, for which pred returns true.
+func (store Store) Filter(pred func(key string, valueTimestamp *ValueTimestamp) bool) Store {
+	store2 := Store{}
+	for key, valueTimestamp := range store {
+		if pred(key, valueTimestamp) {
+			valueTimestampCopy := *valueTimestamp
+			store2[key] = &valueTimestampCopy
+		}
+	}
+	return store2
+}
+
+// Tombstones returns the keys currently marked deleted, mapped to the
+// timestamps of their deletions.
+func (store Store) Tombstones() map[string]int64 {
+	tombstones := map[string]int64{}
+	for key, valueTimestamp := range store {
+		if valueTimestamp.Value == nil {
+			tombstones[key] = valueTimestamp.Timestamp
+		}
+	}
+	return tombstones
+}
+
 // Absorb will update store with any newer items from store2; after Absorb, you
 // should no longer use store2.
 func (store Store) Absorb(store2 Store) {
+	for key, valueTimestamp2 := range store2 {
+		store.absorbEntry(key, valueTimestamp2)
+	}
+}
+
+// AbsorbAll updates store with the newest entry for each key from all of
+// stores. The newest entries are chosen first and then each key is written at
+// most once, so SyncStore.AbsorbAll reports a single Change per key. As with
+// Absorb, you should no longer use any of stores afterwards.
+func (store Store) AbsorbAll(stores ...Store) {
+	store.Absorb(newestEntries(stores))
+}
+
+// newestEntries returns a new Store holding the newest entry for each key
+// from all of stores; the entries themselves are shared, not copied.
+func newestEntries(stores []Store) Store {
+	if len(stores) == 1 {
+		return stores[0]
+	}
+	newest := Store{}
+	for _, store2 := range stores {
+		for key, valueTimestamp2 := range store2 {
+			newest.absorbEntry(key, valueTimestamp2)
+		}
+	}
+	return newest
+}
+
+// contextCheckInterval is how many entries the Context variants of methods
+// process between checks of whether their context is done.
+const contextCheckInterval = 1024
+
+// AbsorbContext is like Absorb but stops early, returning ctx.Err(), if ctx
+// is done before all of store2 has been absorbed. The store is still valid
+// afterwards, holding whichever of store2's entries were absorbed so far.
+func (store Store) AbsorbContext(ctx context.Context, store2 Store) error {
+	i := 0
+	for key, valueTimestamp2 := range store2 {
+		if i%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+		}
+		store.absorbEntry(key, valueTimestamp2)
+		i++
+	}
+	return nil
+}
+
+// progressInterval is how many entries AbsorbWithProgress absorbs between
+// calls to its progress function.
+const progressInterval = 1024
+
+// AbsorbWithProgress is like Absorb but calls progress with how many of
+// store2's entries have been processed so far, out of the total, every so
+// often and once more at the end. If progress returns false, absorbing stops
+// there and AbsorbWithProgress returns false; the store is still valid,
+// holding whichever of store2's entries were absorbed so far.
+func (store Store) AbsorbWithProgress(store2 Store, progress func(done int, total int) bool) bool {
+	done, total := 0, len(store2)
+	for key, valueTimestamp2 := range store2 {
+		store.absorbEntry(key, valueTimestamp2)
+		done++
+		if done%progressInterval == 0 && done < total && !progress(done, total) {
+			return false
+		}
+	}
+	return progress(done, total)
+}
+
+// AbsorbReport is like Absorb but returns the sorted keys that were updated
+// and the sorted keys whose entries from store2 were discarded for not being
+// newer than what store already had.
+func (store Store) AbsorbReport(store2 Store) (updated []string, discarded []string) {
+	for key, valueTimestamp2 := range store2 {
+		if store.absorbEntry(key, valueTimestamp2) {
+			updated = append(updated, key)
+		} else {
+			discarded = append(discarded, key)
+		}
+	}
+	sort.Strings(updated)
+	sort.Strings(discarded)
+	return updated, discarded
+}
+
+// AbsorbCopy is like Absorb but stores copies of the entries from store2, so
+// store2 remains usable afterwards.
+func (store Store) AbsorbCopy(store2 Store) {
 	for key, valueTimestamp2 := range store2 {
 		valueTimestamp := store[key]
-		if valueTimestamp == nil || valueTimestamp.Timestamp < valueTimestamp2.Timestamp {
-			store[key] = valueTimestamp2
+		if valueTimestamp == nil {
+			valueTimestampCopy := *valueTimestamp2
+			store[key] = &valueTimestampCopy
+		} else if valueTimestamp.Timestamp < valueTimestamp2.Timestamp {
+			*valueTimestamp = *valueTimestamp2
 		}
 	}
 }
 
-// Hash returns a computed hash string that can be used to quickly detect if
-// two stores are in sync.
-func (store Store) Hash() string {
-	ks := make([]string, 0, len(store))
+// AbsorbRemapped is like Absorb but any key from store2 found in remap is
+// stored under the key it maps to instead. This lets stores using an older key
+// layout be merged into one using a newer layout; if store2 has entries for
+// both the old and new keys, the newer entry wins as usual.
+func (store Store) AbsorbRemapped(store2 Store, remap map[string]string) {
+	for key, valueTimestamp2 := range store2 {
+		if newKey, ok := remap[key]; ok {
+			key = newKey
+		}
+		store.absorbEntry(key, valueTimestamp2)
+	}
+}
+
+// AbsorbBatched is like Absorb but is for stores guarded by locker; the lock is
+// acquired for at most batchSize entries at a time and released in between so
+// that other goroutines waiting on the lock aren't held up for the whole of a
+// large Absorb. Those goroutines may observe the store partially merged, but
+// each key will always hold either its previous entry or the newer one from
+// store2. The caller should not hold the lock when calling AbsorbBatched, and
+// store2 must not be shared with other goroutines.
+func (store Store) AbsorbBatched(store2 Store, locker sync.Locker, batchSize int) {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	count := 0
+	locker.Lock()
+	for key, valueTimestamp2 := range store2 {
+		if count == batchSize {
+			locker.Unlock()
+			runtime.Gosched()
+			locker.Lock()
+			count = 0
+		}
+		store.absorbEntry(key, valueTimestamp2)
+		count++
+	}
+	locker.Unlock()
+}
+
+// absorbEntry stores valueTimestamp2 for the key if it is newer than the
+// existing entry, returning true if it was stored.
+func (store Store) absorbEntry(key string, valueTimestamp2 *ValueTimestamp) bool {
+	valueTimestamp := store[key]
+	if valueTimestamp == nil || valueTimestamp.Timestamp < valueTimestamp2.Timestamp {
+		store[key] = valueTimestamp2
+		return true
+	}
+	return false
+}
+
+// ModifiedSince returns a new store holding copies of the entries, including
+// deletion markers, with timestamps newer than the timestamp given. Sending
+// the result to another store for it to Absorb is a cheap way to replicate
+// just the recent changes.
+func (store Store) ModifiedSince(timestamp int64) Store {
+	store2 := Store{}
+	for key, valueTimestamp := range store {
+		if valueTimestamp.Timestamp > timestamp {
+			valueTimestampCopy := *valueTimestamp
+			store2[key] = &valueTimestampCopy
+		}
+	}
+	return store2
+}
+
+// OldestTimestamp returns the oldest timestamp of any entry in the store,
+// including deletion markers, or 0 if the store is empty.
+func (store Store) OldestTimestamp() int64 {
+	var oldest int64
+	first := true
+	for _, valueTimestamp := range store {
+		if first || valueTimestamp.Timestamp < oldest {
+			oldest = valueTimestamp.Timestamp
+			first = false
+		}
+	}
+	return oldest
+}
+
+// NewestTimestamp returns the newest timestamp of any entry in the store,
+// including deletion markers, or 0 if the store is empty.
+func (store Store) NewestTimestamp() int64 {
+	var newest int64
+	first := true
+	for _, valueTimestamp := range store {
+		if first || valueTimestamp.Timestamp > newest {
+			newest = valueTimestamp.Timestamp
+			first = false
+		}
+	}
+	return newest
+}
+
+// NewestKeys returns up to n keys, including those marked deleted, in order
+// from most to least recently changed; keys with equal timestamps are in key
+// order. An n less than 0 is treated as 0.
+func (store Store) NewestKeys(n int) []string {
+	if n < 0 {
+		n = 0
+	}
+	ks := store.sortedKeys()
+	sort.SliceStable(ks, func(i, j int) bool {
+		return store[ks[i]].Timestamp > store[ks[j]].Timestamp
+	})
+	if n < len(ks) {
+		ks = ks[:n]
+	}
+	return ks
+}
+
+// PrefixKeys returns the sorted keys beginning with prefix that have values;
+// keys marked deleted are not included.
+func (store Store) PrefixKeys(prefix string) []string {
+	var ks []string
+	for k, valueTimestamp := range store {
+		if valueTimestamp.Value != nil && strings.HasPrefix(k, prefix) {
+			ks = append(ks, k)
+		}
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// PrefixRange calls fn, in key order, for each entry whose key begins with
+// prefix, including deletion markers; if fn returns false the iteration
+// stops.
+func (store Store) PrefixRange(prefix string, fn func(key string, valueTimestamp *ValueTimestamp) bool) {
+	var ks []string
 	for k := range store {
-		ks = append(ks, k)
+		if strings.HasPrefix(k, prefix) {
+			ks = append(ks, k)
+		}
 	}
 	sort.Strings(ks)
-	hasher := fnv.New64a()
 	for _, k := range ks {
-		hasher.Write([]byte(fmt.Sprintf("%s\n%d\n", k, store[k].Timestamp)))
+		if !fn(k, store[k]) {
+			return
+		}
+	}
+}
+
+// RangeKeys returns the sorted keys k with start <= k < end that have values;
+// keys marked deleted are not included. An empty end means there is no upper
+// bound. To page through a large store, pass the last key returned with "\x00"
+// appended as the next start.
+func (store Store) RangeKeys(start string, end string) []string {
+	var ks []string
+	for k, valueTimestamp := range store {
+		if valueTimestamp.Value != nil && k >= start && (end == "" || k < end) {
+			ks = append(ks, k)
+		}
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// ToMap returns the keys and values of the store as a plain map; keys marked
+// deleted are not included.
+func (store Store) ToMap() map[string]string {
+	m := make(map[string]string, len(store))
+	for key, valueTimestamp := range store {
+		if valueTimestamp.Value != nil {
+			m[key] = *valueTimestamp.Value
+		}
+	}
+	return m
+}
+
+// Hash returns a computed hash string that can be used to quickly detect if
+// two stores are in sync. It covers each entry's key, timestamp, and any
+// Flags, so stores that differ only in their entries' flags hash differently.
+func (store Store) Hash() string {
+	return store.HashExcluding()
+}
+
+// HashExcluding is like Hash but ignores any keys beginning with one of the
+// prefixes given; useful for leaving frequently changing keys, like
+// heartbeats, out of comparisons.
+func (store Store) HashExcluding(prefixes ...string) string {
+	hash, _ := store.hash(context.Background(), prefixes)
+	return hash
+}
+
+// HashContext is like Hash but gives up, returning ctx.Err(), if ctx is done
+// before the hash is complete.
+func (store Store) HashContext(ctx context.Context) (string, error) {
+	return store.hash(ctx, nil)
+}
+
+// hash computes the hash for Hash, HashExcluding, and HashContext.
+func (store Store) hash(ctx context.Context, prefixes []string) (string, error) {
+	ks := store.sortedKeys()
+	hasher := fnv.New64a()
+KEYS:
+	for i, k := range ks {
+		if i%contextCheckInterval == 0 {
+			if err := ctx.Err(); err != nil {
+				return "", err
+			}
+		}
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(k, prefix) {
+				continue KEYS
+			}
+		}
+		if valueTimestamp := store[k]; valueTimestamp.Flags != 0 {
+			hasher.Write([]byte(fmt.Sprintf("%s\n%d,%d\n", k, valueTimestamp.Timestamp, valueTimestamp.Flags)))
+		} else {
+			hasher.Write([]byte(fmt.Sprintf("%s\n%d\n", k, valueTimestamp.Timestamp)))
+		}
+	}
+	return fmt.Sprintf("%016x", hasher.Sum64()), nil
+}
+
+// MarshalJSON returns the JSON encoded store. The encoding is canonical: keys
+// are always in sorted order, with no whitespace, and numbers are always plain
+// decimal integers, so equal stores always give byte-identical output that can
+// be diffed, signed, or content-addressed. Strings are escaped as
+// encoding/json escapes them.
+func (store Store) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := store.WriteJSON(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// UnmarshalJSON adds the entries from the JSON encoded b to the store,
+// replacing any existing entries for the same keys, as encoding/json does
+// for maps. Entries may be in the [value,timestamp] tuple format or the object
+// format written by MarshalJSONObjects. Null entries, invalid entries, and
+// duplicate keys give an error naming the key, in which case the store is left
+// unchanged. A JSON null leaves the store unchanged.
+func (store *Store) UnmarshalJSON(b []byte) error {
+	if string(bytes.TrimSpace(b)) == "null" {
+		return nil
 	}
-	return fmt.Sprintf("%016x", hasher.Sum64())
+	store2 := Store{}
+	decoder := json.NewDecoder(bytes.NewReader(b))
+	err := decodeJSONEntries(decoder, func(key string, valueTimestamp *ValueTimestamp) error {
+		if store2[key] != nil {
+			return fmt.Errorf("kvt: duplicate key %q", key)
+		}
+		store2[key] = valueTimestamp
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if _, err = decoder.Token(); err != io.EOF {
+		return fmt.Errorf("kvt: unexpected data after JSON store")
+	}
+	if *store == nil {
+		*store = store2
+		return nil
+	}
+	for key, valueTimestamp := range store2 {
+		(*store)[key] = valueTimestamp
+	}
+	return nil
 }
 
-// String returns the JSON encoded string representation of the store contents.
+// String returns the JSON encoded string representation of the store
+// contents, as MarshalJSON gives.
 func (store Store) String() string {
-	b, err := json.Marshal(store)
+	b, err := store.MarshalJSON()
 	if err != nil {
 		return fmt.Sprintf("error encoding %#v: %#v", store, err)
 	}
@@ -112,11 +639,7 @@ func (store Store) String() string {
 // SimpleString returns a simple key=value[,key=value] string form of the store
 // contents; useful in tests when you want to omit the timestamps.
 func (store Store) SimpleString() string {
-	ks := make([]string, 0, len(store))
-	for k := range store {
-		ks = append(ks, k)
-	}
-	sort.Strings(ks)
+	ks := store.sortedKeys()
 	var msg string
 	for i, k := range ks {
 		if store[k].Value == nil {
@@ -136,48 +659,264 @@ func (store Store) SimpleString() string {
 	return msg
 }
 
+// SimpleStringQuoted is like SimpleString but with keys and values quoted as
+// Go string literals, so the output is unambiguous even when keys or values
+// contain commas, equal signs, or quotes. ParseSimpleStringQuoted reverses it.
+func (store Store) SimpleStringQuoted() string {
+	ks := store.sortedKeys()
+	parts := make([]string, len(ks))
+	for i, k := range ks {
+		if store[k].Value == nil {
+			parts[i] = strconv.Quote(k) + "/deleted"
+		} else {
+			parts[i] = strconv.Quote(k) + "=" + strconv.Quote(*store[k].Value)
+		}
+	}
+	return strings.Join(parts, ",")
+}
+
+// ParseSimpleStringQuoted returns a store built from the output of
+// SimpleStringQuoted, with every entry given the timestamp provided.
+func ParseSimpleStringQuoted(s string, timestamp int64) (Store, error) {
+	store := Store{}
+	for rest := s; rest != ""; {
+		quotedKey, err := strconv.QuotedPrefix(rest)
+		if err != nil {
+			return nil, fmt.Errorf("invalid key at offset %d of: %s", len(s)-len(rest), s)
+		}
+		key, _ := strconv.Unquote(quotedKey)
+		rest = rest[len(quotedKey):]
+		if strings.HasPrefix(rest, "/deleted") {
+			store.DeleteTimestamped(key, timestamp)
+			rest = rest[len("/deleted"):]
+		} else if strings.HasPrefix(rest, "=") {
+			quotedValue, err := strconv.QuotedPrefix(rest[1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid value at offset %d of: %s", len(s)-len(rest)+1, s)
+			}
+			value, _ := strconv.Unquote(quotedValue)
+			store.SetTimestamped(key, value, timestamp)
+			rest = rest[1+len(quotedValue):]
+		} else {
+			return nil, fmt.Errorf("expected = or /deleted at offset %d of: %s", len(s)-len(rest), s)
+		}
+		if rest != "" {
+			if rest[0] != ',' || len(rest) == 1 {
+				return nil, fmt.Errorf("expected , at offset %d of: %s", len(s)-len(rest), s)
+			}
+			rest = rest[1:]
+		}
+	}
+	return store, nil
+}
+
+// Verify checks the store's entries for consistency and returns an error for
+// each problem found: nil entries, keys or values that are not valid UTF-8 (and
+// so would not survive JSON encoding), and timestamps that are not positive.
+// The errors are in key order; nil is returned if no problems are found.
+func (store Store) Verify() []error {
+	var errs []error
+	for _, k := range store.sortedKeys() {
+		valueTimestamp := store[k]
+		if valueTimestamp == nil {
+			errs = append(errs, fmt.Errorf("nil entry for key %q", k))
+			continue
+		}
+		if !utf8.ValidString(k) {
+			errs = append(errs, fmt.Errorf("invalid UTF-8 in key %q", k))
+		}
+		if valueTimestamp.Value != nil && !utf8.ValidString(*valueTimestamp.Value) {
+			errs = append(errs, fmt.Errorf("invalid UTF-8 in value for key %q", k))
+		}
+		if valueTimestamp.Timestamp <= 0 {
+			errs = append(errs, fmt.Errorf("invalid timestamp %d for key %q", valueTimestamp.Timestamp, k))
+		}
+	}
+	return errs
+}
+
+// sortedKeys returns all the keys in the store, including those marked
+// deleted, in sorted order.
+func (store Store) sortedKeys() []string {
+	ks := make([]string, 0, len(store))
+	for k := range store {
+		ks = append(ks, k)
+	}
+	sort.Strings(ks)
+	return ks
+}
+
+// keys returns the keys in the store, in no particular order, with any found
+// in remap replaced by what they map to.
+func (store Store) keys(remap map[string]string) []string {
+	ks := make([]string, 0, len(store))
+	for k := range store {
+		if newKey, ok := remap[k]; ok {
+			k = newKey
+		}
+		ks = append(ks, k)
+	}
+	return ks
+}
+
 // ValueTimestamp is the Value|Timestamp pair stored for each Key. If Value is
 // nil, it indicates a deletion marker. These deletion markers are usually
 // purged after some time using Store.Purge.
+//
+// Flags are optional markers describing the Value; they are reset whenever the
+// Value is replaced by SetTimestamped or DeleteTimestamped. Absorb only takes
+// entries with newer timestamps, so to replicate a change to an entry's Flags,
+// also give the entry a newer timestamp; Hash includes the Flags, so stores
+// that differ only in flags are still seen to be out of sync.
+//
+// Adding Flags changed the struct's shape: composite literals that list the
+// fields by position, such as ValueTimestamp{&value, timestamp}, no longer
+// compile and must name the fields instead, as in
+// ValueTimestamp{Value: &value, Timestamp: timestamp}.
 type ValueTimestamp struct {
 	Value     *string
 	Timestamp int64
+	Flags     Flags
 }
 
 // MarshalJSON returns the JSON encoded version of valueTimestamp or an error.
+// The encoding is [value,timestamp], or [value,timestamp,flags] if any Flags
+// are set.
 func (valueTimestamp *ValueTimestamp) MarshalJSON() ([]byte, error) {
+	if valueTimestamp.Flags != 0 {
+		return json.Marshal([]interface{}{valueTimestamp.Value, valueTimestamp.Timestamp, valueTimestamp.Flags})
+	}
 	return json.Marshal([]interface{}{valueTimestamp.Value, valueTimestamp.Timestamp})
 }
 
 // MarshalJSON loads valueTimestamp with data from the JSON encoded b or
-// returns an error.
+// returns an error. Both the [value,timestamp] tuple format and the object
+// format written by MarshalJSONObject are accepted.
 func (valueTimestamp *ValueTimestamp) UnmarshalJSON(b []byte) error {
-	jsonValueTimestamp := make([]interface{}, 0, 2)
-	if err := json.Unmarshal(b, &jsonValueTimestamp); err != nil {
+	if trimmed := bytes.TrimLeft(b, " \t\r\n"); len(trimmed) > 0 && trimmed[0] == '{' {
+		return valueTimestamp.unmarshalJSONObject(b)
+	}
+	var parts []json.RawMessage
+	if err := json.Unmarshal(b, &parts); err != nil {
 		return err
 	}
-	if len(jsonValueTimestamp) != 2 {
-		return fmt.Errorf("expected [value,timestamp] from: %s", b)
+	if len(parts) != 2 && len(parts) != 3 {
+		return fmt.Errorf("expected [value,timestamp] or [value,timestamp,flags] from: %s", b)
 	}
-	if jsonValueTimestamp[0] == nil {
-		valueTimestamp.Value = nil
-	} else if value, ok := jsonValueTimestamp[0].(string); !ok {
+	value, ok := rawJSONString(parts[0])
+	if !ok {
 		return fmt.Errorf("invalid value from: %s", b)
-	} else {
-		valueTimestamp.Value = &value
 	}
-	if t, ok := jsonValueTimestamp[1].(float64); !ok || float64(int64(t)) != t {
+	valueTimestamp.Value = value
+	if t, ok := rawJSONInt(parts[1]); !ok {
 		return fmt.Errorf("invalid timestamp from: %s", b)
 	} else {
-		valueTimestamp.Timestamp = int64(t)
+		valueTimestamp.Timestamp = t
+	}
+	valueTimestamp.Flags = 0
+	if len(parts) == 3 {
+		if f, ok := rawJSONInt(parts[2]); !ok || f < 0 || f > math.MaxUint32 {
+			return fmt.Errorf("invalid flags from: %s", b)
+		} else {
+			valueTimestamp.Flags = Flags(f)
+		}
 	}
 	return nil
 }
 
+// jsonInt returns the integer held by the json.Number v. Integral numbers
+// written with fractions or exponents, such as 1.0 or 1e3, are accepted
+// though MarshalJSON never writes them; they may lose precision beyond 2^53.
+func jsonInt(v interface{}) (int64, bool) {
+	number, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if i, err := strconv.ParseInt(string(number), 10, 64); err == nil {
+		return i, true
+	}
+	f, err := strconv.ParseFloat(string(number), 64)
+	if err != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// equal returns true if valueTimestamp and valueTimestamp2 hold the same
+// value, timestamp, and flags.
+func (valueTimestamp *ValueTimestamp) equal(valueTimestamp2 *ValueTimestamp) bool {
+	if valueTimestamp.Timestamp != valueTimestamp2.Timestamp || valueTimestamp.Flags != valueTimestamp2.Flags {
+		return false
+	}
+	if valueTimestamp.Value == nil || valueTimestamp2.Value == nil {
+		return valueTimestamp.Value == valueTimestamp2.Value
+	}
+	return *valueTimestamp.Value == *valueTimestamp2.Value
+}
+
 // String returns a quick string representation of valueTimestamp.
 func (valueTimestamp *ValueTimestamp) String() string {
+	var s string
 	if valueTimestamp.Value == nil {
-		return fmt.Sprintf("nil,%d", valueTimestamp.Timestamp)
+		s = fmt.Sprintf("nil,%d", valueTimestamp.Timestamp)
+	} else {
+		s = fmt.Sprintf("%s,%d", *valueTimestamp.Value, valueTimestamp.Timestamp)
+	}
+	if valueTimestamp.Flags != 0 {
+		s += "," + valueTimestamp.Flags.String()
+	}
+	return s
+}
+
+// Flags is a bitfield of markers stored with each entry. Unknown bits are
+// preserved as-is so stores written by newer versions of this package can pass
+// through older ones intact.
+type Flags uint32
+
+const (
+	// FlagEncrypted indicates the Value is encrypted.
+	FlagEncrypted Flags = 1 << iota
+	// FlagCompressed indicates the Value is compressed.
+	FlagCompressed
+	// FlagPinned indicates the entry should be kept even when it would
+	// otherwise be discarded, such as by a purge.
+	FlagPinned
+	// FlagLiveness indicates the entry is a liveness marker, such as a
+	// heartbeat, rather than configuration data.
+	FlagLiveness
+)
+
+var flagNames = []string{"encrypted", "compressed", "pinned", "liveness"}
+
+// Has returns true if all the bits in flag are set.
+func (flags Flags) Has(flag Flags) bool {
+	return flags&flag == flag
+}
+
+// With returns flags with the bits in flag set.
+func (flags Flags) With(flag Flags) Flags {
+	return flags | flag
+}
+
+// Without returns flags with the bits in flag cleared.
+func (flags Flags) Without(flag Flags) Flags {
+	return flags &^ flag
+}
+
+// String returns the names of the flags set, separated by |; unknown bits are
+// shown in hex.
+func (flags Flags) String() string {
+	var names []string
+	for i, name := range flagNames {
+		if flags.Has(1 << uint(i)) {
+			names = append(names, name)
+		}
+	}
+	if unknown := flags >> uint(len(flagNames)); unknown != 0 {
+		names = append(names, fmt.Sprintf("0x%x", uint32(unknown<<uint(len(flagNames)))))
+	}
+	if len(names) == 0 {
+		return "0"
 	}
-	return fmt.Sprintf("%s,%d", *valueTimestamp.Value, valueTimestamp.Timestamp)
+	return strings.Join(names, "|")
 }
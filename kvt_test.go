@@ -1,6 +1,8 @@
 package kvt_test
 
 import (
+	"encoding/json"
+	"math"
 	"testing"
 
 	"github.com/gholt/kvt"
@@ -17,7 +19,11 @@ func TestValueTimestampUnmarshalJunk(t *testing.T) {
 func TestValueTimestampUnmarshalJunk2(t *testing.T) {
 	vt := &kvt.ValueTimestamp{}
 	err := vt.UnmarshalJSON([]byte(`[1,2,3]`))
-	if err == nil || err.Error() != "expected [value,timestamp] from: [1,2,3]" {
+	if err == nil || err.Error() != "invalid value from: [1,2,3]" {
+		t.Fatal(err)
+	}
+	err = vt.UnmarshalJSON([]byte(`[1,2,3,4]`))
+	if err == nil || err.Error() != "expected [value,timestamp] or [value,timestamp,flags] from: [1,2,3,4]" {
 		t.Fatal(err)
 	}
 }
@@ -45,3 +51,168 @@ func TestValueTimestampUnmarshalJunk5(t *testing.T) {
 		t.Fatal(err)
 	}
 }
+
+func TestValueTimestampUnmarshalJunk6(t *testing.T) {
+	vt := &kvt.ValueTimestamp{}
+	err := vt.UnmarshalJSON([]byte(`["one",2,-1]`))
+	if err == nil || err.Error() != `invalid flags from: ["one",2,-1]` {
+		t.Fatal(err)
+	}
+}
+
+func TestValueTimestampFlagsReset(t *testing.T) {
+	one := "one"
+	store := kvt.Store{"A": {Value: &one, Timestamp: 1, Flags: kvt.FlagPinned}}
+	store.SetTimestamped("A", "two", 2)
+	if store["A"].Flags != 0 {
+		t.Fatal(store["A"])
+	}
+	store["A"].Flags = kvt.FlagPinned
+	store.DeleteTimestamped("A", 3)
+	if store["A"].Flags != 0 {
+		t.Fatal(store["A"])
+	}
+}
+
+func TestPurgeKeepsPinned(t *testing.T) {
+	store := kvt.Store{"A": {Timestamp: 1, Flags: kvt.FlagPinned}, "B": {Timestamp: 1}}
+	store.Purge(10)
+	if s := store.String(); s != `{"A":[null,1,4]}` {
+		t.Fatal(s)
+	}
+}
+
+func TestHashIncludesFlags(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	unflagged := store.Hash()
+	store["A"].Flags = kvt.FlagPinned
+	if store.Hash() == unflagged {
+		t.Fatal("flags not included in hash")
+	}
+	store["A"].Flags = 0
+	if store.Hash() != unflagged {
+		t.Fatal("hash without flags changed")
+	}
+}
+
+func TestParseSimpleStringQuotedJunk(t *testing.T) {
+	for _, junk := range []string{`A=one`, `"A"`, `"A"x`, `"A"="one",`, `"A"="one"x`, `"A"="one`} {
+		if _, err := kvt.ParseSimpleStringQuoted(junk, 1); err == nil {
+			t.Fatal(junk)
+		}
+	}
+}
+
+func TestMarshalJSONCanonical(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("b", "two", math.MaxInt64)
+	store.SetTimestamped("a", "one", 1602000000123456789)
+	store.DeleteTimestamped("c", math.MinInt64)
+	store["c"].Flags = kvt.Flags(math.MaxUint32)
+	b, err := json.Marshal(store)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"a":["one",1602000000123456789],"b":["two",9223372036854775807],"c":[null,-9223372036854775808,4294967295]}`
+	if string(b) != want {
+		t.Fatal(string(b))
+	}
+	// Round trips are exact, so the output is byte-identical.
+	store2 := kvt.Store{}
+	if err = json.Unmarshal(b, &store2); err != nil {
+		t.Fatal(err)
+	}
+	if s := store2.String(); s != want {
+		t.Fatal(s)
+	}
+	// Integral numbers written other ways are still accepted.
+	vt := &kvt.ValueTimestamp{}
+	if err = vt.UnmarshalJSON([]byte(`["one",1e3,4.0]`)); err != nil || vt.Timestamp != 1000 || vt.Flags != 4 {
+		t.Fatal(vt, err)
+	}
+}
+
+func TestValueTimestampUnmarshalObjectJunk(t *testing.T) {
+	for _, junk := range []string{
+		`{}`,
+		`{"value":"one"}`,
+		`{"ts":1}`,
+		`{"value":1,"ts":1}`,
+		`{"value":"one","ts":"1"}`,
+		`{"value":"one","ts":1.5}`,
+		`{"value":"one","ts":1,"flags":-1}`,
+		`{"value":"one","ts":1,"extra":1}`,
+	} {
+		vt := &kvt.ValueTimestamp{}
+		if err := vt.UnmarshalJSON([]byte(junk)); err == nil {
+			t.Errorf("expected error from %s", junk)
+		}
+	}
+	vt := &kvt.ValueTimestamp{}
+	if err := vt.UnmarshalJSON([]byte(` {"ts":1602000000123456789,"value":"one"}`)); err != nil || vt.String() != "one,1602000000123456789" {
+		t.Fatal(vt, err)
+	}
+}
+
+func TestStoreUnmarshalJSONValidation(t *testing.T) {
+	for junk, want := range map[string]string{
+		`{"A":["one",1],"B":null}`:        `kvt: null entry for key "B"`,
+		`{"A":["one",1],"B":["two","x"]}`: `kvt: invalid entry for key "B": invalid timestamp from: ["two","x"]`,
+		`{"A":["one",1],"A":["two",2]}`:   `kvt: duplicate key "A"`,
+		`{"A":{"value":"one"}}`:           `kvt: invalid entry for key "A": expected {"value":value,"ts":timestamp} from: {"value":"one"}`,
+		`["A"]`:                           `kvt: expected JSON "{", not [`,
+		`{"A":["one",1e400]}`:             `kvt: invalid entry for key "A": invalid timestamp from: ["one",1e400]`,
+	} {
+		store := kvt.Store{}
+		store.SetTimestamped("existing", "x", 1)
+		err := json.Unmarshal([]byte(junk), &store)
+		if err == nil || err.Error() != want {
+			t.Errorf("%s: got %v, want %s", junk, err, want)
+		}
+		if len(store) != 1 {
+			t.Errorf("%s: store changed to %s", junk, store)
+		}
+	}
+	var store kvt.Store
+	if err := json.Unmarshal([]byte(`{"A":["one",1],"B":{"value":null,"ts":2}}`), &store); err != nil || store.String() != `{"A":["one",1],"B":[null,2]}` {
+		t.Fatal(store, err)
+	}
+	if err := json.Unmarshal([]byte(`null`), &store); err != nil || len(store) != 2 {
+		t.Fatal(store, err)
+	}
+	// Zero and negative timestamps, as from FromMap(m, 0), round trip.
+	zero := kvt.Store{}
+	zero.SetTimestamped("A", "one", 0)
+	zero.DeleteTimestamped("B", -1)
+	var zero2 kvt.Store
+	if err := json.Unmarshal([]byte(zero.String()), &zero2); err != nil || zero2.String() != `{"A":["one",0],"B":[null,-1]}` {
+		t.Fatal(zero2, err)
+	}
+}
+
+func TestNoOpWritesReported(t *testing.T) {
+	store := kvt.Store{}
+	store.DeleteTimestamped("A", kvt.MaxTimestamp)
+	store.SetTimestamped("B", "future", kvt.MaxTimestamp)
+	if actual, loaded := store.GetOrSet("A", "one"); actual != "" || !loaded {
+		t.Error(actual, loaded)
+	}
+	if previous, loaded, swapped := store.Swap("B", "two"); previous != "future" || !loaded || swapped {
+		t.Error(previous, loaded, swapped)
+	}
+	if value, loaded, deleted := store.LoadAndDelete("B"); value != "future" || !loaded || deleted {
+		t.Error(value, loaded, deleted)
+	}
+	if s := store.String(); s != `{"A":[null,9223372036854775807],"B":["future",9223372036854775807]}` {
+		t.Fatal(s)
+	}
+}
+
+func TestNewestKeysNegative(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	if ks := store.NewestKeys(-1); len(ks) != 0 {
+		t.Fatal(ks)
+	}
+}
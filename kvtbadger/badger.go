@@ -0,0 +1,158 @@
+//go:build badger
+
+package kvtbadger
+
+import (
+	"encoding/binary"
+	"errors"
+
+	badger "github.com/dgraph-io/badger/v4"
+	"github.com/gholt/kvt"
+)
+
+// Backend is a kvt.Backend storing each entry as its own Badger key; see
+// encode for the value format. Deltas are merged straight
+// into the database, keeping whichever entry is newest, so Load never has
+// deltas to replay.
+type Backend struct {
+	db *badger.DB
+}
+
+var _ kvt.Backend = &Backend{}
+
+// Open opens, or creates, the Badger database in dir.
+func Open(dir string) (*Backend, error) {
+	db, err := badger.Open(badger.DefaultOptions(dir).WithLogger(nil))
+	if err != nil {
+		return nil, err
+	}
+	return &Backend{db: db}, nil
+}
+
+// Load returns every entry in the database.
+func (backend *Backend) Load() (kvt.Store, error) {
+	store := kvt.Store{}
+	err := backend.db.View(func(txn *badger.Txn) error {
+		it := txn.NewIterator(badger.DefaultIteratorOptions)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			item := it.Item()
+			var valueTimestamp *kvt.ValueTimestamp
+			err := item.Value(func(b []byte) (err error) {
+				valueTimestamp, err = decode(b)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			store[string(item.KeyCopy(nil))] = valueTimestamp
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Save replaces the database's entries with those of the store.
+func (backend *Backend) Save(store kvt.Store) error {
+	batch := backend.db.NewWriteBatch()
+	defer batch.Cancel()
+	err := backend.db.View(func(txn *badger.Txn) error {
+		options := badger.DefaultIteratorOptions
+		options.PrefetchValues = false
+		it := txn.NewIterator(options)
+		defer it.Close()
+		for it.Rewind(); it.Valid(); it.Next() {
+			key := it.Item().KeyCopy(nil)
+			if store[string(key)] == nil {
+				if err := batch.Delete(key); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for key, valueTimestamp := range store {
+		if err = batch.Set([]byte(key), encode(valueTimestamp)); err != nil {
+			return err
+		}
+	}
+	return batch.Flush()
+}
+
+// AppendDelta merges the delta into the database in one transaction, keeping
+// whichever entry for each key has the newer timestamp.
+func (backend *Backend) AppendDelta(delta kvt.Store) error {
+	return backend.db.Update(func(txn *badger.Txn) error {
+		for key, valueTimestamp := range delta {
+			item, err := txn.Get([]byte(key))
+			if err == nil {
+				var existing *kvt.ValueTimestamp
+				err = item.Value(func(b []byte) (err error) {
+					existing, err = decode(b)
+					return err
+				})
+				if err != nil {
+					return err
+				}
+				if existing.Timestamp >= valueTimestamp.Timestamp {
+					continue
+				}
+			} else if err != badger.ErrKeyNotFound {
+				return err
+			}
+			if err = txn.Set([]byte(key), encode(valueTimestamp)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// Close closes the database.
+func (backend *Backend) Close() error {
+	return backend.db.Close()
+}
+
+// encode returns the database value for an entry: an 8 byte big endian
+// timestamp, 4 bytes of flags, then, for entries that aren't deletion
+// markers, a 1 byte followed by the value itself.
+func encode(valueTimestamp *kvt.ValueTimestamp) []byte {
+	b := make([]byte, 12, 13+len(stringValue(valueTimestamp)))
+	binary.BigEndian.PutUint64(b, uint64(valueTimestamp.Timestamp))
+	binary.BigEndian.PutUint32(b[8:], uint32(valueTimestamp.Flags))
+	if valueTimestamp.Value != nil {
+		b = append(b, 1)
+		b = append(b, *valueTimestamp.Value...)
+	}
+	return b
+}
+
+// decode returns the entry for a database value written by encode.
+func decode(b []byte) (*kvt.ValueTimestamp, error) {
+	if len(b) < 12 || (len(b) > 12 && b[12] != 1) {
+		return nil, errors.New("kvtbadger: invalid entry")
+	}
+	valueTimestamp := &kvt.ValueTimestamp{
+		Timestamp: int64(binary.BigEndian.Uint64(b)),
+		Flags:     kvt.Flags(binary.BigEndian.Uint32(b[8:])),
+	}
+	if len(b) > 12 {
+		value := string(b[13:])
+		valueTimestamp.Value = &value
+	}
+	return valueTimestamp, nil
+}
+
+// stringValue returns the entry's value, or "" for a deletion marker.
+func stringValue(valueTimestamp *kvt.ValueTimestamp) string {
+	if valueTimestamp.Value == nil {
+		return ""
+	}
+	return *valueTimestamp.Value
+}
@@ -0,0 +1,55 @@
+//go:build badger
+
+package kvtbadger_test
+
+import (
+	"testing"
+
+	"github.com/gholt/kvt"
+	"github.com/gholt/kvt/kvtbadger"
+)
+
+func TestBackendRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	backend, err := kvtbadger.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.SetTimestamped("B", "two", 2)
+	store.DeleteTimestamped("C", 3)
+	if err = backend.Save(store); err != nil {
+		t.Fatal(err)
+	}
+	delta := kvt.Store{}
+	delta.SetTimestamped("A", "uno", 4)
+	delta.SetTimestamped("B", "stale", 1)
+	delta.SetTimestamped("D", "four", 5)
+	if err = backend.AppendDelta(delta); err != nil {
+		t.Fatal(err)
+	}
+	if err = backend.Close(); err != nil {
+		t.Fatal(err)
+	}
+	backend, err = kvtbadger.Open(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer backend.Close()
+	loaded, err := backend.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := loaded.String(); s != `{"A":["uno",4],"B":["two",2],"C":[null,3],"D":["four",5]}` {
+		t.Fatal(s)
+	}
+	store2 := kvt.Store{}
+	store2.SetTimestamped("E", "five", 6)
+	if err = backend.Save(store2); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = backend.Load(); err != nil || loaded.String() != `{"E":["five",6]}` {
+		t.Fatal(loaded, err)
+	}
+}
@@ -0,0 +1,12 @@
+// Package kvtbadger provides a kvt.Backend backed by a Badger database, for
+// stores with heavy write churn that want an LSM tree underneath kvt's merge
+// semantics.
+//
+// The implementation requires github.com/dgraph-io/badger/v4, which must be
+// listed in the requirements of the module building it, and is only built
+// and tested with the badger build tag:
+//
+//	go get github.com/dgraph-io/badger/v4
+//	go build -tags badger
+//	go test -tags badger ./kvtbadger
+package kvtbadger
@@ -0,0 +1,126 @@
+// Package kvtetcd provides a kvt.Backend that keeps a store's entries under a
+// key prefix in etcd, so teams already running etcd can layer kvt's offline
+// merge semantics on top of it.
+//
+// The package doesn't depend on any particular etcd client; wrap yours, such
+// as go.etcd.io/etcd/client/v3, to satisfy Client.
+package kvtetcd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/gholt/kvt"
+)
+
+// KeyValue is an etcd key with its value and the revision at which it was
+// last modified.
+type KeyValue struct {
+	Key         string
+	Value       []byte
+	ModRevision int64
+}
+
+// Client is the subset of an etcd client that Backend needs.
+type Client interface {
+	// GetPrefix returns every key beginning with prefix.
+	GetPrefix(ctx context.Context, prefix string) ([]KeyValue, error)
+	// Get returns the key, with ok false if it doesn't exist.
+	Get(ctx context.Context, key string) (kv KeyValue, ok bool, err error)
+	// PutIfRevision sets the key's value in a transaction comparing its
+	// ModRevision to modRevision, 0 meaning the key doesn't exist, and
+	// returns whether they matched and the value was set.
+	PutIfRevision(ctx context.Context, key string, value []byte, modRevision int64) (bool, error)
+}
+
+// Backend is a kvt.Backend keeping each entry as the etcd key prefix+key,
+// with the JSON encoded [value,timestamp] as its value. Both deltas and saved
+// stores are merged a key at a time with compare-and-swap transactions,
+// keeping whichever entry is newest, so several processes may safely save and
+// append under the same prefix. Save therefore never removes keys from etcd;
+// entries dropped locally, such as tombstones removed by Purge, stay there.
+type Backend struct {
+	client Client
+	prefix string
+}
+
+var _ kvt.Backend = &Backend{}
+
+// New returns a Backend for the entries under prefix in etcd.
+func New(client Client, prefix string) *Backend {
+	return &Backend{client: client, prefix: prefix}
+}
+
+// Load returns every entry under the prefix.
+func (backend *Backend) Load() (kvt.Store, error) {
+	kvs, err := backend.client.GetPrefix(context.Background(), backend.prefix)
+	if err != nil {
+		return nil, err
+	}
+	store := make(kvt.Store, len(kvs))
+	for _, kv := range kvs {
+		valueTimestamp := &kvt.ValueTimestamp{}
+		if err = json.Unmarshal(kv.Value, valueTimestamp); err != nil {
+			return nil, fmt.Errorf("%s: %s", kv.Key, err)
+		}
+		store[strings.TrimPrefix(kv.Key, backend.prefix)] = valueTimestamp
+	}
+	return store, nil
+}
+
+// Save merges the store's entries into etcd just as AppendDelta does.
+// Replacing the entries outright would discard newer ones written by other
+// processes since this store last loaded them.
+func (backend *Backend) Save(store kvt.Store) error {
+	return backend.AppendDelta(store)
+}
+
+// AppendDelta merges the delta into etcd, keeping whichever entry for each
+// key has the newer timestamp. Each key is updated atomically, retrying if
+// another process changed it in the meantime, but the delta as a whole is
+// not.
+func (backend *Backend) AppendDelta(delta kvt.Store) error {
+	ctx := context.Background()
+	for key, valueTimestamp := range delta {
+		b, err := json.Marshal(valueTimestamp)
+		if err != nil {
+			return err
+		}
+		if err = backend.merge(ctx, backend.prefix+key, valueTimestamp.Timestamp, b); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merge sets the etcd key to b unless it already has an entry with a newer
+// or equal timestamp.
+func (backend *Backend) merge(ctx context.Context, key string, timestamp int64, b []byte) error {
+	for {
+		kv, ok, err := backend.client.Get(ctx, key)
+		if err != nil {
+			return err
+		}
+		var modRevision int64
+		if ok {
+			existing := &kvt.ValueTimestamp{}
+			if err = json.Unmarshal(kv.Value, existing); err != nil {
+				return fmt.Errorf("%s: %s", key, err)
+			}
+			if existing.Timestamp >= timestamp {
+				return nil
+			}
+			modRevision = kv.ModRevision
+		}
+		if ok, err = backend.client.PutIfRevision(ctx, key, b, modRevision); err != nil || ok {
+			return err
+		}
+	}
+}
+
+// Close does nothing; the caller remains responsible for closing the client.
+func (backend *Backend) Close() error {
+	return nil
+}
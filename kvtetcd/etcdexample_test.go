@@ -0,0 +1,105 @@
+package kvtetcd_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/gholt/kvt"
+	"github.com/gholt/kvt/kvtetcd"
+)
+
+// memoryClient is an in-memory Client, standing in for a wrapped etcd client.
+type memoryClient struct {
+	lock     sync.Mutex
+	revision int64
+	kvs      map[string]kvtetcd.KeyValue
+}
+
+func (client *memoryClient) GetPrefix(ctx context.Context, prefix string) ([]kvtetcd.KeyValue, error) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	var kvs []kvtetcd.KeyValue
+	for key, kv := range client.kvs {
+		if strings.HasPrefix(key, prefix) {
+			kvs = append(kvs, kv)
+		}
+	}
+	sort.Slice(kvs, func(i, j int) bool { return kvs[i].Key < kvs[j].Key })
+	return kvs, nil
+}
+
+func (client *memoryClient) Get(ctx context.Context, key string) (kvtetcd.KeyValue, bool, error) {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	kv, ok := client.kvs[key]
+	return kv, ok, nil
+}
+
+func (client *memoryClient) Put(ctx context.Context, key string, value []byte) error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	client.revision++
+	client.kvs[key] = kvtetcd.KeyValue{Key: key, Value: append([]byte(nil), value...), ModRevision: client.revision}
+	return nil
+}
+
+func (client *memoryClient) PutIfRevision(ctx context.Context, key string, value []byte, modRevision int64) (bool, error) {
+	client.lock.Lock()
+	if client.kvs[key].ModRevision != modRevision {
+		client.lock.Unlock()
+		return false, nil
+	}
+	client.lock.Unlock()
+	return true, client.Put(ctx, key, value)
+}
+
+func (client *memoryClient) Delete(ctx context.Context, key string) error {
+	client.lock.Lock()
+	defer client.lock.Unlock()
+	delete(client.kvs, key)
+	return nil
+}
+
+func Example() {
+	client := &memoryClient{kvs: map[string]kvtetcd.KeyValue{}}
+	backend := kvtetcd.New(client, "/config/app/")
+
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.SetTimestamped("B", "two", 1)
+	if err := backend.Save(store); err != nil {
+		panic(err)
+	}
+
+	// Two nodes, having worked offline, append their changes; the newer entry
+	// for each key wins regardless of the order they arrive in.
+	node1 := kvt.Store{}
+	node1.SetTimestamped("A", "node1", 3)
+	node1.SetTimestamped("B", "node1", 2)
+	node2 := kvt.Store{}
+	node2.SetTimestamped("A", "node2", 2)
+	node2.DeleteTimestamped("B", 3)
+	for _, delta := range []kvt.Store{node1, node2} {
+		if err := backend.AppendDelta(delta); err != nil {
+			panic(err)
+		}
+	}
+
+	// Saving the first, now stale, store again doesn't undo their changes.
+	if err := backend.Save(store); err != nil {
+		panic(err)
+	}
+	fmt.Println(backend.Load())
+	kvs, _ := client.GetPrefix(context.Background(), "")
+	for _, kv := range kvs {
+		fmt.Printf("%s %s\n", kv.Key, kv.Value)
+	}
+
+	// Output:
+	// {"A":["node1",3],"B":[null,3]} <nil>
+	// /config/app/A ["node1",3]
+	// /config/app/B [null,3]
+}
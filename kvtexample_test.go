@@ -1,7 +1,10 @@
 package kvt_test
 
 import (
+	"context"
 	"fmt"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/gholt/kvt"
@@ -81,6 +84,14 @@ func ExampleStore() {
 	// Store1: A=one,B/deleted,C=four,D=five,E=eight,F/deleted
 }
 
+func ExampleFromMap() {
+	store := kvt.FromMap(map[string]string{"A": "one", "B": "two"}, 1)
+	fmt.Println(store)
+
+	// Output:
+	// {"A":["one",1],"B":["two",1]}
+}
+
 func ExampleStore_Get() {
 	store := kvt.Store{}
 	store.Set("A", "one")
@@ -136,6 +147,134 @@ func ExampleStore_DeleteTimestamped() {
 	// {"A":[null,1483326245000000006],"B":["two",2],"C":[null,4]}
 }
 
+func ExampleStore_GetOrSet() {
+	store := kvt.Store{}
+	store.Set("A", "one")
+	fmt.Println(store.GetOrSet("A", "default"))
+	fmt.Println(store.GetOrSet("B", "default"))
+	fmt.Println(store.SimpleString())
+
+	// Output:
+	// one true
+	// default false
+	// A=one,B=default
+}
+
+func ExampleStore_Swap() {
+	store := kvt.Store{}
+	fmt.Println(store.Swap("A", "one"))
+	fmt.Println(store.Swap("A", "two"))
+	fmt.Println(store.Get("A"))
+
+	// Output:
+	//  false true
+	// one true true
+	// two
+}
+
+func ExampleStore_LoadAndDelete() {
+	store := kvt.Store{}
+	store.Set("A", "one")
+	fmt.Println(store.LoadAndDelete("A"))
+	fmt.Println(store.LoadAndDelete("A"))
+	fmt.Println(store.SimpleString())
+
+	// Output:
+	// one true true
+	//  false true
+	// A/deleted
+}
+
+func ExampleStore_Touch() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 1)
+	fmt.Println(store.Touch("A"), store.Touch("B"), store.Touch("C"))
+	fmt.Println(store["A"].Timestamp > 1, store.SimpleString())
+
+	// Output:
+	// true false false
+	// true A=one,B/deleted
+}
+
+func ExampleStore_TouchTimestamped() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 5)
+	fmt.Println(store.TouchTimestamped("A", 3))
+	fmt.Println(store.TouchTimestamped("A", 7))
+	fmt.Println(store)
+
+	// Output:
+	// false
+	// true
+	// {"A":["one",7]}
+}
+
+func ExampleStore_Rename() {
+	store := kvt.Store{}
+	store.Set("old", "one")
+	fmt.Println(store.Rename("old", "new"))
+	fmt.Println(store.Rename("missing", "other"))
+	fmt.Println(store.SimpleString())
+
+	// Output:
+	// true
+	// false
+	// new=one,old/deleted
+}
+
+func ExampleStore_RenameTimestamped() {
+	store := kvt.Store{}
+	store.SetTimestamped("old", "one", 1)
+	store.SetTimestamped("taken", "two", 5)
+	fmt.Println(store.RenameTimestamped("old", "taken", 3))
+	fmt.Println(store.RenameTimestamped("old", "new", 3))
+	fmt.Println(store)
+
+	// Output:
+	// false
+	// true
+	// {"new":["one",3],"old":[null,3],"taken":["two",5]}
+}
+
+func ExampleStore_TransformValues() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "http://one", 1)
+	store.SetTimestamped("B", "http://two", 2)
+	store.DeleteTimestamped("C", 3)
+	store.TransformValues(func(key string, value string) string {
+		return strings.Replace(value, "http:", "https:", 1)
+	})
+	fmt.Println(store)
+
+	// Output:
+	// {"A":["https://one",1],"B":["https://two",2],"C":[null,3]}
+}
+
+func ExampleStore_Clear() {
+	store := kvt.Store{}
+	store.Set("A", "one")
+	store.Set("B", "two")
+	store.Delete("C")
+	store.Clear()
+	fmt.Println(store.SimpleString())
+
+	// Output:
+	// A/deleted,B/deleted,C/deleted
+}
+
+func ExampleStore_ClearTimestamped() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.SetTimestamped("B", "two", 3)
+	store.DeleteTimestamped("C", 1)
+	store.ClearTimestamped(2)
+	fmt.Println(store)
+
+	// Output:
+	// {"A":[null,2],"B":["two",3],"C":[null,1]}
+}
+
 func ExampleStore_Purge() {
 	store := kvt.Store{}
 	now := time.Date(2017, 1, 2, 3, 4, 5, 6, time.UTC)
@@ -151,6 +290,45 @@ func ExampleStore_Purge() {
 	// After: {"B":[null,1479726245000000006],"C":[null,1479729845000000006]}
 }
 
+func ExampleStore_Copy() {
+	store1 := kvt.Store{}
+	store1.SetTimestamped("A", "one", 1)
+	store2 := store1.Copy()
+	store2.SetTimestamped("A", "two", 2)
+	fmt.Println("Store1:", store1)
+	fmt.Println("Store2:", store2)
+
+	// Output:
+	// Store1: {"A":["one",1]}
+	// Store2: {"A":["two",2]}
+}
+
+func ExampleStore_Filter() {
+	store := kvt.Store{}
+	store.SetTimestamped("tenant1/A", "one", 1)
+	store.SetTimestamped("tenant2/B", "two", 2)
+	store.DeleteTimestamped("tenant1/C", 3)
+	tenant1 := store.Filter(func(key string, valueTimestamp *kvt.ValueTimestamp) bool {
+		return strings.HasPrefix(key, "tenant1/")
+	})
+	fmt.Println(tenant1)
+
+	// Output:
+	// {"tenant1/A":["one",1],"tenant1/C":[null,3]}
+}
+
+func ExampleStore_Tombstones() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	store.SetTimestamped("C", "three", 3)
+	store.DeleteTimestamped("C", 4)
+	fmt.Println(store.Tombstones())
+
+	// Output:
+	// map[B:2 C:4]
+}
+
 func ExampleStore_Absorb() {
 	// Create store with a few items.
 	store1 := kvt.Store{}
@@ -188,6 +366,197 @@ func ExampleStore_Absorb() {
 	// Store1: A=one,B/deleted,C=four,D=five,E=eight,F/deleted
 }
 
+func ExampleStore_AbsorbReport() {
+	store1 := kvt.Store{}
+	store1.SetTimestamped("A", "one", 1)
+	store1.SetTimestamped("B", "two", 3)
+	store2 := kvt.Store{}
+	store2.SetTimestamped("A", "uno", 2)
+	store2.SetTimestamped("B", "dos", 2)
+	store2.DeleteTimestamped("C", 2)
+	updated, discarded := store1.AbsorbReport(store2)
+	fmt.Println("Updated:", updated)
+	fmt.Println("Discarded:", discarded)
+
+	// Output:
+	// Updated: [A C]
+	// Discarded: [B]
+}
+
+func ExampleStore_AbsorbCopy() {
+	store1 := kvt.Store{}
+	store1.SetTimestamped("A", "one", 1)
+	store2 := kvt.Store{}
+	store2.SetTimestamped("A", "two", 2)
+	store2.SetTimestamped("B", "three", 2)
+	store1.AbsorbCopy(store2)
+	// Unlike with Absorb, store2 can keep being used without affecting store1.
+	store2.SetTimestamped("A", "four", 3)
+	store2.DeleteTimestamped("B", 3)
+	fmt.Println("Store1:", store1)
+	fmt.Println("Store2:", store2)
+
+	// Output:
+	// Store1: {"A":["two",2],"B":["three",2]}
+	// Store2: {"A":["four",3],"B":[null,3]}
+}
+
+func ExampleStore_AbsorbRemapped() {
+	store1 := kvt.Store{}
+	store1.SetTimestamped("net/iface0/mtu", "1500", 1)
+	// store2 is from a node still using the old key layout.
+	store2 := kvt.Store{}
+	store2.SetTimestamped("mtu0", "9000", 2)
+	store2.SetTimestamped("hostname", "alpha", 2)
+	store1.AbsorbRemapped(store2, map[string]string{"mtu0": "net/iface0/mtu"})
+	fmt.Println(store1)
+
+	// Output:
+	// {"hostname":["alpha",2],"net/iface0/mtu":["9000",2]}
+}
+
+func ExampleStore_AbsorbBatched() {
+	var lock sync.RWMutex
+	store1 := kvt.Store{}
+	store1.SetTimestamped("A", "one", 1)
+	store2 := kvt.Store{}
+	for i := 0; i < 1000; i++ {
+		store2.SetTimestamped(fmt.Sprintf("key%04d", i), "value", 2)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		// Readers get a turn every 100 entries rather than waiting for the
+		// whole merge to finish.
+		store1.AbsorbBatched(store2, &lock, 100)
+		close(done)
+	}()
+	lock.RLock()
+	_ = store1.Get("A")
+	lock.RUnlock()
+	<-done
+
+	fmt.Println(len(store1))
+
+	// Output:
+	// 1001
+}
+
+func ExampleStore_ModifiedSince() {
+	store1 := kvt.Store{}
+	store1.SetTimestamped("A", "one", 1)
+	store1.SetTimestamped("B", "two", 2)
+	store1.DeleteTimestamped("C", 3)
+	store2 := kvt.Store{}
+	store2.SetTimestamped("A", "one", 1)
+	store2.SetTimestamped("C", "three", 1)
+
+	// store2 was last synced at timestamp 1, so only send what's newer.
+	delta := store1.ModifiedSince(1)
+	fmt.Println("Delta:", delta)
+	store2.Absorb(delta)
+	fmt.Println("Store2:", store2)
+
+	// Output:
+	// Delta: {"B":["two",2],"C":[null,3]}
+	// Store2: {"A":["one",1],"B":["two",2],"C":[null,3]}
+}
+
+func ExampleStore_OldestTimestamp() {
+	store := kvt.Store{}
+	fmt.Println(store.OldestTimestamp(), store.NewestTimestamp())
+	store.SetTimestamped("A", "one", 5)
+	store.DeleteTimestamped("B", 2)
+	store.SetTimestamped("C", "three", 9)
+	fmt.Println(store.OldestTimestamp(), store.NewestTimestamp())
+
+	// Output:
+	// 0 0
+	// 2 9
+}
+
+func ExampleStore_NewestKeys() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 5)
+	store.DeleteTimestamped("B", 9)
+	store.SetTimestamped("C", "three", 9)
+	store.SetTimestamped("D", "four", 1)
+	fmt.Println(store.NewestKeys(3))
+	fmt.Println(store.NewestKeys(10))
+
+	// Output:
+	// [B C A]
+	// [B C A D]
+}
+
+func ExampleStore_PrefixKeys() {
+	store := kvt.Store{}
+	store.Set("net/iface0/mtu", "1500")
+	store.Set("net/iface0/addr", "10.0.0.1")
+	store.Set("net/iface1/mtu", "9000")
+	store.Delete("net/iface0/gateway")
+	store.Set("disk/sda/size", "1T")
+	fmt.Println(store.PrefixKeys("net/iface0/"))
+	fmt.Println(store.PrefixKeys("net/"))
+	fmt.Println(store.PrefixKeys("nothing/"))
+
+	// Output:
+	// [net/iface0/addr net/iface0/mtu]
+	// [net/iface0/addr net/iface0/mtu net/iface1/mtu]
+	// []
+}
+
+func ExampleStore_PrefixRange() {
+	store := kvt.Store{}
+	store.SetTimestamped("net/iface0/mtu", "1500", 1)
+	store.SetTimestamped("net/iface0/addr", "10.0.0.1", 2)
+	store.DeleteTimestamped("net/iface0/gateway", 3)
+	store.SetTimestamped("net/iface1/mtu", "9000", 4)
+	store.PrefixRange("net/iface0/", func(key string, valueTimestamp *kvt.ValueTimestamp) bool {
+		fmt.Println(key, valueTimestamp)
+		return true
+	})
+	store.PrefixRange("net/", func(key string, valueTimestamp *kvt.ValueTimestamp) bool {
+		fmt.Println("first only:", key)
+		return false
+	})
+
+	// Output:
+	// net/iface0/addr 10.0.0.1,2
+	// net/iface0/gateway nil,3
+	// net/iface0/mtu 1500,1
+	// first only: net/iface0/addr
+}
+
+func ExampleStore_RangeKeys() {
+	store := kvt.Store{}
+	for _, k := range []string{"a", "b", "c", "d", "e"} {
+		store.Set(k, k)
+	}
+	store.Delete("c")
+	fmt.Println(store.RangeKeys("b", "e"))
+	fmt.Println(store.RangeKeys("b", ""))
+	fmt.Println(store.RangeKeys("", "b"))
+	fmt.Println(store.RangeKeys("b\x00", "e"))
+
+	// Output:
+	// [b d]
+	// [b d e]
+	// [a]
+	// [d]
+}
+
+func ExampleStore_ToMap() {
+	store := kvt.Store{}
+	store.Set("A", "one")
+	store.Set("B", "two")
+	store.Delete("C")
+	fmt.Println(store.ToMap())
+
+	// Output:
+	// map[A:one B:two]
+}
+
 func ExampleStore_Hash() {
 	store1 := kvt.Store{}
 	now := time.Date(2017, 1, 2, 3, 4, 5, 6, time.UTC).UnixNano()
@@ -209,6 +578,103 @@ func ExampleStore_Hash() {
 	// store2 now has hash 3d670f76bcf310f4
 }
 
+func ExampleStore_HashExcluding() {
+	store1 := kvt.Store{}
+	store1.SetTimestamped("config/A", "one", 1)
+	store1.SetTimestamped("heartbeat/node1", "alive", 100)
+	store2 := kvt.Store{}
+	store2.SetTimestamped("config/A", "one", 1)
+	store2.SetTimestamped("heartbeat/node1", "alive", 200)
+	fmt.Println(store1.Hash() == store2.Hash())
+	fmt.Println(store1.HashExcluding("heartbeat/") == store2.HashExcluding("heartbeat/"))
+
+	// Output:
+	// false
+	// true
+}
+
+func ExampleStore_HashContext() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	hash, err := store.HashContext(context.Background())
+	fmt.Println(hash == store.Hash(), err)
+
+	// A canceled context stops the hash early.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	hash, err = store.HashContext(ctx)
+	fmt.Printf("%q %v\n", hash, err)
+
+	// Output:
+	// true <nil>
+	// "" context canceled
+}
+
+func ExampleStore_AbsorbAll() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	peer1 := kvt.Store{}
+	peer1.SetTimestamped("A", "uno", 2)
+	peer1.SetTimestamped("B", "two", 1)
+	peer2 := kvt.Store{}
+	peer2.DeleteTimestamped("B", 3)
+	peer2.SetTimestamped("C", "three", 1)
+	store.AbsorbAll(peer1, peer2)
+	fmt.Println(store)
+
+	// Output:
+	// {"A":["uno",2],"B":[null,3],"C":["three",1]}
+}
+
+func ExampleStore_AbsorbWithProgress() {
+	store := kvt.Store{}
+	store2 := kvt.Store{}
+	for i := 0; i < 3000; i++ {
+		store2.SetTimestamped(fmt.Sprintf("k%04d", i), "v", 1)
+	}
+	completed := store.AbsorbWithProgress(store2, func(done, total int) bool {
+		fmt.Printf("%d/%d\n", done, total)
+		return true
+	})
+	fmt.Println(completed, len(store))
+
+	// Stopping after the first progress report leaves a partial merge.
+	store = kvt.Store{}
+	completed = store.AbsorbWithProgress(store2, func(done, total int) bool { return false })
+	fmt.Println(completed, len(store))
+
+	// Output:
+	// 1024/3000
+	// 2048/3000
+	// 3000/3000
+	// true 3000
+	// false 1024
+}
+
+func ExampleStore_AbsorbContext() {
+	store1 := kvt.Store{}
+	store1.SetTimestamped("A", "one", 1)
+	store2 := kvt.Store{}
+	store2.SetTimestamped("A", "uno", 2)
+	store2.SetTimestamped("B", "two", 2)
+	fmt.Println(store1.AbsorbContext(context.Background(), store2))
+	fmt.Println(store1.SimpleString())
+
+	// A canceled context leaves the store as it was.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	store3 := kvt.Store{}
+	store3.SetTimestamped("C", "three", 3)
+	fmt.Println(store1.AbsorbContext(ctx, store3))
+	fmt.Println(store1.SimpleString())
+
+	// Output:
+	// <nil>
+	// A=uno,B=two
+	// context canceled
+	// A=uno,B=two
+}
+
 func ExampleStore_String() {
 	store := kvt.Store{}
 	now := time.Date(2017, 1, 2, 3, 4, 5, 6, time.UTC).UnixNano()
@@ -233,6 +699,48 @@ func ExampleStore_SimpleString() {
 	// A=one,B/deleted
 }
 
+func ExampleStore_SimpleStringQuoted() {
+	store := kvt.Store{}
+	store.Set("A", "one,two")
+	store.Set("B=C", `say "hi"`)
+	store.Delete("D")
+	fmt.Println(store.SimpleString())
+	fmt.Println(store.SimpleStringQuoted())
+
+	// Output:
+	// A=one,two,B=C=say "hi",D/deleted
+	// "A"="one,two","B=C"="say \"hi\"","D"/deleted
+}
+
+func ExampleParseSimpleStringQuoted() {
+	store, err := kvt.ParseSimpleStringQuoted(`"A"="one,two","B=C"="say \"hi\"","D"/deleted`, 1)
+	fmt.Println(store, err)
+	_, err = kvt.ParseSimpleStringQuoted(`"A"=one`, 1)
+	fmt.Println(err)
+
+	// Output:
+	// {"A":["one,two",1],"B=C":["say \"hi\"",1],"D":[null,1]} <nil>
+	// invalid value at offset 4 of: "A"=one
+}
+
+func ExampleStore_Verify() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	fmt.Println(store.Verify())
+	store.SetTimestamped("B", "two", -2)
+	store["C"] = nil
+	store.SetTimestamped("D\xff", "four", 4)
+	for _, err := range store.Verify() {
+		fmt.Println(err)
+	}
+
+	// Output:
+	// []
+	// invalid timestamp -2 for key "B"
+	// nil entry for key "C"
+	// invalid UTF-8 in key "D\xff"
+}
+
 func ExampleValueTimestamp() {
 	one := "one"
 	vtA := &kvt.ValueTimestamp{Value: &one, Timestamp: 1}
@@ -240,7 +748,7 @@ func ExampleValueTimestamp() {
 	store := kvt.Store{"A": vtA, "B": vtB}
 	fmt.Println(store)
 	// A bit simpler:
-	store = kvt.Store{"A": {&one, 1}, "B": {nil, 2}}
+	store = kvt.Store{"A": {Value: &one, Timestamp: 1}, "B": {Timestamp: 2}}
 	fmt.Println(store)
 
 	// Output:
@@ -254,10 +762,13 @@ func ExampleValueTimestamp_MarshalJSON() {
 	fmt.Println(string(b), err)
 	b, err = (&kvt.ValueTimestamp{Value: nil, Timestamp: 2}).MarshalJSON()
 	fmt.Println(string(b), err)
+	b, err = (&kvt.ValueTimestamp{Value: &one, Timestamp: 3, Flags: kvt.FlagPinned}).MarshalJSON()
+	fmt.Println(string(b), err)
 
 	// Output:
 	// ["one",1] <nil>
 	// [null,2] <nil>
+	// ["one",3,4] <nil>
 }
 
 func ExampleValueTimestamp_UnmarshalJSON() {
@@ -267,10 +778,14 @@ func ExampleValueTimestamp_UnmarshalJSON() {
 	vt = &kvt.ValueTimestamp{}
 	err = vt.UnmarshalJSON([]byte(`[null,2]`))
 	fmt.Println(vt, err)
+	vt = &kvt.ValueTimestamp{}
+	err = vt.UnmarshalJSON([]byte(`["one",3,5]`))
+	fmt.Println(vt, err)
 
 	// Output:
 	// one,1 <nil>
 	// nil,2 <nil>
+	// one,3,encrypted|pinned <nil>
 }
 
 func ExampleValueTimestamp_String() {
@@ -284,3 +799,17 @@ func ExampleValueTimestamp_String() {
 	// one,1 nil,2
 	// one,1 nil,2
 }
+
+func ExampleFlags() {
+	var flags kvt.Flags
+	flags = flags.With(kvt.FlagCompressed | kvt.FlagPinned)
+	fmt.Println(flags, flags.Has(kvt.FlagPinned), flags.Has(kvt.FlagEncrypted))
+	flags = flags.Without(kvt.FlagPinned)
+	fmt.Println(flags, flags.Has(kvt.FlagPinned))
+	fmt.Println(kvt.Flags(0), kvt.Flags(1<<8|1))
+
+	// Output:
+	// compressed|pinned true false
+	// compressed false
+	// 0 encrypted|0x100
+}
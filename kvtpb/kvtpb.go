@@ -0,0 +1,221 @@
+// Package kvtpb provides the protocol buffer form of a kvt.Store, as defined
+// by kvt.proto in this directory, so kvt data can be carried inside gRPC APIs
+// without manual translation code.
+//
+// The package doesn't depend on a protobuf runtime: Store and ValueTimestamp
+// are plain structs, not generated messages, and Marshal and Unmarshal
+// implement the wire format. They can't be used as fields of generated
+// messages; a .proto that imports kvt.proto needs Go code generated for it
+// into a package of its own, as described in kvt.proto. The bytes from
+// Marshal can then be passed to proto.Unmarshal with that package's Store,
+// and those from proto.Marshal to Unmarshal.
+//
+// Other protobuf implementations reject strings that aren't valid UTF-8, so
+// keys and values should be valid UTF-8 when exchanging stores with them.
+package kvtpb
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sort"
+
+	"github.com/gholt/kvt"
+)
+
+// ValueTimestamp is the ValueTimestamp message.
+type ValueTimestamp struct {
+	// Value is nil for a deletion marker.
+	Value     *string
+	Timestamp int64
+	Flags     uint32
+}
+
+// Store is the Store message.
+type Store struct {
+	Entries map[string]*ValueTimestamp
+}
+
+// ToProto returns the Store message for the store.
+func ToProto(store kvt.Store) *Store {
+	pb := &Store{Entries: make(map[string]*ValueTimestamp, len(store))}
+	for key, valueTimestamp := range store {
+		pbValueTimestamp := &ValueTimestamp{Timestamp: valueTimestamp.Timestamp, Flags: uint32(valueTimestamp.Flags)}
+		if valueTimestamp.Value != nil {
+			value := *valueTimestamp.Value
+			pbValueTimestamp.Value = &value
+		}
+		pb.Entries[key] = pbValueTimestamp
+	}
+	return pb
+}
+
+// FromProto returns the store held by the Store message. Nil entries, which
+// only a hand built message could have, are skipped.
+func FromProto(pb *Store) kvt.Store {
+	store := make(kvt.Store, len(pb.Entries))
+	for key, pbValueTimestamp := range pb.Entries {
+		if pbValueTimestamp == nil {
+			continue
+		}
+		valueTimestamp := &kvt.ValueTimestamp{Timestamp: pbValueTimestamp.Timestamp, Flags: kvt.Flags(pbValueTimestamp.Flags)}
+		if pbValueTimestamp.Value != nil {
+			value := *pbValueTimestamp.Value
+			valueTimestamp.Value = &value
+		}
+		store[key] = valueTimestamp
+	}
+	return store
+}
+
+// Wire types used by the messages.
+const (
+	wireVarint  = 0
+	wireFixed64 = 1
+	wireBytes   = 2
+	wireFixed32 = 5
+)
+
+// Marshal returns the wire encoding of the Store message, with the entries
+// in key order so the same store always encodes the same way.
+func (store *Store) Marshal() ([]byte, error) {
+	keys := make([]string, 0, len(store.Entries))
+	for key := range store.Entries {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	var b, entry, valueTimestamp []byte
+	for _, key := range keys {
+		valueTimestamp = store.Entries[key].appendTo(valueTimestamp[:0])
+		entry = appendBytes(entry[:0], 1, []byte(key))
+		entry = appendBytes(entry, 2, valueTimestamp)
+		b = appendBytes(b, 1, entry)
+	}
+	return b, nil
+}
+
+// appendTo appends the wire encoding of the ValueTimestamp message to b.
+func (valueTimestamp *ValueTimestamp) appendTo(b []byte) []byte {
+	if valueTimestamp == nil {
+		return b
+	}
+	if valueTimestamp.Value != nil {
+		b = appendBytes(b, 1, []byte(*valueTimestamp.Value))
+	}
+	if valueTimestamp.Timestamp != 0 {
+		b = binary.AppendUvarint(b, 2<<3|wireVarint)
+		b = binary.AppendUvarint(b, uint64(valueTimestamp.Timestamp))
+	}
+	if valueTimestamp.Flags != 0 {
+		b = binary.AppendUvarint(b, 3<<3|wireVarint)
+		b = binary.AppendUvarint(b, uint64(valueTimestamp.Flags))
+	}
+	return b
+}
+
+// appendBytes appends a length delimited field to b.
+func appendBytes(b []byte, field uint64, data []byte) []byte {
+	b = binary.AppendUvarint(b, field<<3|wireBytes)
+	b = binary.AppendUvarint(b, uint64(len(data)))
+	return append(b, data...)
+}
+
+// Unmarshal replaces the Store message with the one wire encoded in b.
+// Unknown fields are skipped, as protobuf requires.
+func (store *Store) Unmarshal(b []byte) error {
+	entries := map[string]*ValueTimestamp{}
+	err := readFields(b, func(field uint64, wireType uint64, v uint64, data []byte) error {
+		if field != 1 || wireType != wireBytes {
+			return nil
+		}
+		var key string
+		valueTimestamp := &ValueTimestamp{}
+		err := readFields(data, func(field uint64, wireType uint64, v uint64, data []byte) error {
+			switch {
+			case field == 1 && wireType == wireBytes:
+				key = string(data)
+			case field == 2 && wireType == wireBytes:
+				return valueTimestamp.unmarshal(data)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		entries[key] = valueTimestamp
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("kvtpb: %s", err)
+	}
+	store.Entries = entries
+	return nil
+}
+
+// unmarshal sets the ValueTimestamp message's fields from the wire encoding
+// in b.
+func (valueTimestamp *ValueTimestamp) unmarshal(b []byte) error {
+	return readFields(b, func(field uint64, wireType uint64, v uint64, data []byte) error {
+		switch {
+		case field == 1 && wireType == wireBytes:
+			value := string(data)
+			valueTimestamp.Value = &value
+		case field == 2 && wireType == wireVarint:
+			valueTimestamp.Timestamp = int64(v)
+		case field == 3 && wireType == wireVarint:
+			valueTimestamp.Flags = uint32(v)
+		}
+		return nil
+	})
+}
+
+// errTruncated is returned when a message ends partway through a field.
+var errTruncated = errors.New("truncated message")
+
+// readFields calls fn with each field wire encoded in b: its number, its wire
+// type, and its value, as v for varints and fixed width types and as data for
+// length delimited ones.
+func readFields(b []byte, fn func(field uint64, wireType uint64, v uint64, data []byte) error) error {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return errTruncated
+		}
+		b = b[n:]
+		field, wireType := tag>>3, tag&7
+		if field == 0 {
+			return errors.New("invalid field number 0")
+		}
+		var v uint64
+		var data []byte
+		switch wireType {
+		case wireVarint:
+			if v, n = binary.Uvarint(b); n <= 0 {
+				return errTruncated
+			}
+			b = b[n:]
+		case wireFixed64:
+			if len(b) < 8 {
+				return errTruncated
+			}
+			v, b = binary.LittleEndian.Uint64(b), b[8:]
+		case wireBytes:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || length > uint64(len(b)-n) {
+				return errTruncated
+			}
+			data, b = b[n:n+int(length)], b[n+int(length):]
+		case wireFixed32:
+			if len(b) < 4 {
+				return errTruncated
+			}
+			v, b = uint64(binary.LittleEndian.Uint32(b)), b[4:]
+		default:
+			return fmt.Errorf("unsupported wire type %d", wireType)
+		}
+		if err := fn(field, wireType, v, data); err != nil {
+			return err
+		}
+	}
+	return nil
+}
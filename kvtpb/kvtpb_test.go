@@ -0,0 +1,59 @@
+package kvtpb
+
+import (
+	"math"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestRoundTrip(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1602000000123456789)
+	store.SetTimestamped("", "", 0)
+	store.SetTimestamped("negative", "v", math.MinInt64)
+	store.DeleteTimestamped("B", -2)
+	store["B"].Flags = kvt.Flags(math.MaxUint32)
+	b, err := ToProto(store).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pb := &Store{Entries: map[string]*ValueTimestamp{"stale": {}}}
+	if err = pb.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := FromProto(pb).String(), store.String(); a != b {
+		t.Fatal(a, b)
+	}
+	// Any cut within a single entry store is detected.
+	b, err = ToProto(kvt.Store{"A": store["A"]}).Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(b); i++ {
+		if err = pb.Unmarshal(b[:i]); err == nil {
+			t.Fatal("no error truncated to", i, "of", len(b))
+		}
+	}
+}
+
+func TestUnmarshalSkipsUnknownFields(t *testing.T) {
+	b := []byte{
+		0x08, 0x96, 0x01, // field 1 as a varint, the wrong type
+		0x11, 1, 2, 3, 4, 5, 6, 7, 8, // field 2, fixed64
+		0x1d, 1, 2, 3, 4, // field 3, fixed32
+		0x0a, 0x0c, 0x0a, 0x01, 'A', 0x12, 0x07, 0x0a, 0x03, 'o', 'n', 'e', 0x10, 0x01,
+	}
+	pb := &Store{}
+	if err := pb.Unmarshal(b); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := FromProto(pb).String(), `{"A":["one",1]}`; a != b {
+		t.Fatal(a, b)
+	}
+	for _, junk := range [][]byte{{0x00}, {0x0b}, {0x0a, 0x05, 0x00}} {
+		if err := pb.Unmarshal(junk); err == nil {
+			t.Errorf("expected error from %x", junk)
+		}
+	}
+}
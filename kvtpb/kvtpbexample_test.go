@@ -0,0 +1,25 @@
+package kvtpb_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+	"github.com/gholt/kvt/kvtpb"
+)
+
+func Example() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	b, err := kvtpb.ToProto(store).Marshal()
+	fmt.Printf("% x %v\n", b, err)
+
+	pb := &kvtpb.Store{}
+	fmt.Println(pb.Unmarshal(b))
+	fmt.Println(kvtpb.FromProto(pb))
+
+	// Output:
+	// 0a 0c 0a 01 41 12 07 0a 03 6f 6e 65 10 01 0a 07 0a 01 42 12 02 10 02 <nil>
+	// <nil>
+	// {"A":["one",1],"B":[null,2]}
+}
@@ -0,0 +1,131 @@
+// Package kvtredis provides a kvt.Backend that mirrors a store into a Redis
+// hash, so services can share kvt data through an existing Redis deployment
+// and hydrate new stores from it.
+//
+// The package doesn't depend on any particular Redis client; wrap yours to
+// satisfy Client.
+package kvtredis
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/gholt/kvt"
+)
+
+// Client is the subset of a Redis client that Backend needs.
+type Client interface {
+	// HGetAll returns all the fields and values of the hash at key.
+	HGetAll(ctx context.Context, key string) (map[string]string, error)
+	// Eval runs the Lua script with the keys and args given, discarding the
+	// result.
+	Eval(ctx context.Context, script string, keys []string, args ...interface{}) error
+}
+
+// Backend is a kvt.Backend keeping each entry as a field of one Redis hash.
+// Both deltas and saved stores are merged by a Lua script that keeps whichever
+// entry is newest, so several processes may safely save and append to the same
+// hash. Save therefore never removes entries from the hash; entries dropped
+// locally, such as tombstones removed by Purge, stay in Redis.
+type Backend struct {
+	client Client
+	key    string
+}
+
+var _ kvt.Backend = &Backend{}
+
+// New returns a Backend for the Redis hash at key.
+func New(client Client, key string) *Backend {
+	return &Backend{client: client, key: key}
+}
+
+// mergeScript sets each field, value pair in ARGV unless the hash already has
+// an entry with a newer or equal timestamp. Encoded values begin with their
+// timestamps in a form that sorts as strings.
+const mergeScript = `
+for i = 1, #ARGV, 2 do
+	local existing = redis.call('HGET', KEYS[1], ARGV[i])
+	if not existing or string.sub(existing, 1, 16) < string.sub(ARGV[i+1], 1, 16) then
+		redis.call('HSET', KEYS[1], ARGV[i], ARGV[i+1])
+	end
+end
+`
+
+// Load returns every entry in the hash.
+func (backend *Backend) Load() (kvt.Store, error) {
+	fields, err := backend.client.HGetAll(context.Background(), backend.key)
+	if err != nil {
+		return nil, err
+	}
+	store := make(kvt.Store, len(fields))
+	for key, s := range fields {
+		valueTimestamp, err := decode(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s %q: %s", backend.key, key, err)
+		}
+		store[key] = valueTimestamp
+	}
+	return store, nil
+}
+
+// Save merges the store's entries into the hash, atomically, just as
+// AppendDelta does. Replacing the hash outright would discard newer entries
+// written by other processes since this store last loaded them.
+func (backend *Backend) Save(store kvt.Store) error {
+	return backend.AppendDelta(store)
+}
+
+// AppendDelta merges the delta into the hash, atomically, keeping whichever
+// entry for each key has the newer timestamp.
+func (backend *Backend) AppendDelta(delta kvt.Store) error {
+	return backend.client.Eval(context.Background(), mergeScript, []string{backend.key}, args(delta)...)
+}
+
+// Close does nothing; the caller remains responsible for closing the client.
+func (backend *Backend) Close() error {
+	return nil
+}
+
+// args returns the store's entries as field, value pairs for the scripts.
+func args(store kvt.Store) []interface{} {
+	a := make([]interface{}, 0, len(store)*2)
+	for key, valueTimestamp := range store {
+		a = append(a, key, encode(valueTimestamp))
+	}
+	return a
+}
+
+// encode returns the hash value for an entry: 16 hex digits of timestamp,
+// offset so that the digits sort in the same order as the timestamps, 8 hex
+// digits of flags, and then "d" for a deletion marker or "v" followed by the
+// value.
+func encode(valueTimestamp *kvt.ValueTimestamp) string {
+	s := fmt.Sprintf("%016x%08x", uint64(valueTimestamp.Timestamp)^(1<<63), uint32(valueTimestamp.Flags))
+	if valueTimestamp.Value == nil {
+		return s + "d"
+	}
+	return s + "v" + *valueTimestamp.Value
+}
+
+// decode returns the entry for a hash value written by encode.
+func decode(s string) (*kvt.ValueTimestamp, error) {
+	if len(s) < 25 || (s[24] != 'd' && s[24] != 'v') || (s[24] == 'd' && len(s) != 25) {
+		return nil, errors.New("invalid entry")
+	}
+	timestamp, err := strconv.ParseUint(s[:16], 16, 64)
+	if err != nil {
+		return nil, errors.New("invalid entry timestamp")
+	}
+	flags, err := strconv.ParseUint(s[16:24], 16, 32)
+	if err != nil {
+		return nil, errors.New("invalid entry flags")
+	}
+	valueTimestamp := &kvt.ValueTimestamp{Timestamp: int64(timestamp ^ (1 << 63)), Flags: kvt.Flags(flags)}
+	if s[24] == 'v' {
+		value := s[25:]
+		valueTimestamp.Value = &value
+	}
+	return valueTimestamp, nil
+}
@@ -0,0 +1,42 @@
+package kvtredis
+
+import (
+	"math"
+	"sort"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestEncodeSortsByTimestamp(t *testing.T) {
+	timestamps := []int64{math.MinInt64, -1 << 40, -1, 0, 1, 1 << 40, 1<<62 + 1, math.MaxInt64}
+	var encoded []string
+	for _, timestamp := range timestamps {
+		encoded = append(encoded, encode(&kvt.ValueTimestamp{Timestamp: timestamp}))
+	}
+	if !sort.StringsAreSorted(encoded) {
+		t.Fatal(encoded)
+	}
+}
+
+func TestEncodeDecode(t *testing.T) {
+	value := "some value"
+	for _, valueTimestamp := range []*kvt.ValueTimestamp{
+		{Value: &value, Timestamp: 1602000000123456789, Flags: kvt.FlagPinned},
+		{Timestamp: -5},
+		{Value: new(string), Timestamp: 0},
+	} {
+		decoded, err := decode(encode(valueTimestamp))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a, b := decoded.String(), valueTimestamp.String(); a != b {
+			t.Fatal(a, b)
+		}
+	}
+	for _, s := range []string{"", "0000000000000000", "000000000000000000000000x", "000000000000000000000000dx", "z00000000000000000000000d"} {
+		if _, err := decode(s); err == nil {
+			t.Errorf("expected error from %q", s)
+		}
+	}
+}
@@ -0,0 +1,142 @@
+// Package kvts3 provides a kvt.Backend that keeps snapshots of a store in
+// S3-compatible object storage, so nodes without local disks can persist a
+// store and new nodes can bootstrap from the latest snapshot.
+//
+// The package doesn't depend on any particular object storage SDK; wrap
+// yours to satisfy Bucket.
+package kvts3
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/gholt/kvt"
+)
+
+// Bucket is the subset of an object storage client that Backend needs.
+type Bucket interface {
+	// Put stores data as the named object, replacing any existing one.
+	Put(ctx context.Context, name string, data []byte) error
+	// Get returns the data of the named object.
+	Get(ctx context.Context, name string) ([]byte, error)
+	// List returns the names of all objects starting with prefix.
+	List(ctx context.Context, prefix string) ([]string, error)
+	// Delete removes the named object.
+	Delete(ctx context.Context, name string) error
+}
+
+// Backend is a kvt.Backend saving each snapshot as a new object named
+// prefix+"snapshot-"+timestamp+".json", with the timestamp zero padded so
+// that the names sort by age.
+//
+// Several nodes may share a prefix. Load absorbs every retained snapshot, so
+// no node's entries are lost to another's newer named snapshot, and Save
+// absorbs the retained snapshots into the one it uploads before pruning the
+// oldest. Two Saves racing may still prune each other's snapshot with a keep
+// of 1; use a larger keep when nodes share a prefix.
+//
+// Uploading an object per write would be slow and costly, so AppendDelta
+// returns kvt.ErrDeltasUnsupported; Backend is meant to be used with an
+// AutoSaver, which only saves whole snapshots, rather than a WAL.
+type Backend struct {
+	bucket Bucket
+	prefix string
+	keep   int
+}
+
+var _ kvt.Backend = &Backend{}
+
+// New returns a Backend storing snapshots in bucket under prefix, keeping the
+// newest keep snapshots and deleting older ones after each Save; keep less
+// than 1 is treated as 1.
+func New(bucket Bucket, prefix string, keep int) *Backend {
+	if keep < 1 {
+		keep = 1
+	}
+	return &Backend{bucket: bucket, prefix: prefix, keep: keep}
+}
+
+// snapshots returns the names of the snapshot objects, oldest first.
+func (backend *Backend) snapshots(ctx context.Context) ([]string, error) {
+	names, err := backend.bucket.List(ctx, backend.prefix+"snapshot-")
+	if err != nil {
+		return nil, err
+	}
+	var snapshots []string
+	for _, name := range names {
+		if strings.HasSuffix(name, ".json") {
+			snapshots = append(snapshots, name)
+		}
+	}
+	sort.Strings(snapshots)
+	return snapshots, nil
+}
+
+// Load returns the retained snapshots absorbed into one Store, or an empty
+// Store if there are none.
+func (backend *Backend) Load() (kvt.Store, error) {
+	return backend.load(context.Background())
+}
+
+func (backend *Backend) load(ctx context.Context) (kvt.Store, error) {
+	snapshots, err := backend.snapshots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	store := kvt.Store{}
+	for _, name := range snapshots {
+		b, err := backend.bucket.Get(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		snapshot := kvt.Store{}
+		if err = json.Unmarshal(b, &snapshot); err != nil {
+			return nil, fmt.Errorf("%s: %s", name, err)
+		}
+		store.Absorb(snapshot)
+	}
+	return store, nil
+}
+
+// Save uploads a new snapshot of the store with the retained snapshots
+// absorbed into it, and then deletes all but the newest snapshots, as
+// configured with New. The store itself is not modified.
+func (backend *Backend) Save(store kvt.Store) error {
+	ctx := context.Background()
+	merged, err := backend.load(ctx)
+	if err != nil {
+		return err
+	}
+	merged.Absorb(store)
+	b, err := json.Marshal(merged)
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%ssnapshot-%020d.json", backend.prefix, uint64(kvt.Now()))
+	if err = backend.bucket.Put(ctx, name, b); err != nil {
+		return err
+	}
+	snapshots, err := backend.snapshots(ctx)
+	if err != nil {
+		return err
+	}
+	for i := 0; i < len(snapshots)-backend.keep; i++ {
+		if err = backend.bucket.Delete(ctx, snapshots[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// AppendDelta always returns kvt.ErrDeltasUnsupported.
+func (backend *Backend) AppendDelta(delta kvt.Store) error {
+	return kvt.ErrDeltasUnsupported
+}
+
+// Close does nothing; the caller remains responsible for the bucket client.
+func (backend *Backend) Close() error {
+	return nil
+}
@@ -0,0 +1,93 @@
+package kvts3_test
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gholt/kvt"
+	"github.com/gholt/kvt/kvts3"
+)
+
+// memoryBucket is an in-memory Bucket, standing in for a wrapped S3 client.
+type memoryBucket struct {
+	lock    sync.Mutex
+	objects map[string][]byte
+}
+
+func (bucket *memoryBucket) Put(ctx context.Context, name string, data []byte) error {
+	bucket.lock.Lock()
+	defer bucket.lock.Unlock()
+	bucket.objects[name] = append([]byte(nil), data...)
+	return nil
+}
+
+func (bucket *memoryBucket) Get(ctx context.Context, name string) ([]byte, error) {
+	bucket.lock.Lock()
+	defer bucket.lock.Unlock()
+	data, ok := bucket.objects[name]
+	if !ok {
+		return nil, fmt.Errorf("no such object %q", name)
+	}
+	return data, nil
+}
+
+func (bucket *memoryBucket) List(ctx context.Context, prefix string) ([]string, error) {
+	bucket.lock.Lock()
+	defer bucket.lock.Unlock()
+	var names []string
+	for name := range bucket.objects {
+		if strings.HasPrefix(name, prefix) {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+func (bucket *memoryBucket) Delete(ctx context.Context, name string) error {
+	bucket.lock.Lock()
+	defer bucket.lock.Unlock()
+	delete(bucket.objects, name)
+	return nil
+}
+
+func Example() {
+	bucket := &memoryBucket{objects: map[string][]byte{}}
+	backend := kvts3.New(bucket, "stores/app/", 2)
+
+	// One node saves snapshots as its store changes; usually this would be
+	// done by an AutoSaver.
+	store := kvt.Store{}
+	for i, key := range []string{"A", "B", "C"} {
+		store.SetTimestamped(key, "value", int64(i+1))
+		if err := backend.Save(store); err != nil {
+			panic(err)
+		}
+	}
+	names, _ := bucket.List(context.Background(), "")
+	fmt.Println(len(names))
+
+	// Another node saves its own store, with an older entry; the first
+	// node's entries are kept as the retained snapshots are merged.
+	other := kvt.Store{}
+	other.SetTimestamped("D", "value", 1)
+	if err := kvts3.New(bucket, "stores/app/", 2).Save(other); err != nil {
+		panic(err)
+	}
+
+	// A new node bootstraps from the merged snapshots.
+	syncStore := kvt.NewSyncStore(nil)
+	autoSaver := syncStore.StartAutoSave(kvts3.New(bucket, "stores/app/", 2), time.Minute)
+	defer autoSaver.Close()
+	loaded, err := backend.Load()
+	syncStore.Absorb(loaded)
+	fmt.Println(syncStore, err)
+
+	// Output:
+	// 2
+	// {"A":["value",1],"B":["value",2],"C":["value",3],"D":["value",1]} <nil>
+}
@@ -0,0 +1,125 @@
+// Package kvtsqlite provides a kvt.Backend that keeps a store in a SQLite
+// table with key, value, timestamp, and flags columns, so other tools can
+// query the entries with SQL while kvt handles merging.
+//
+// The package uses database/sql and works with any SQLite driver; import the
+// driver of your choice and pass the opened *sql.DB to New.
+package kvtsqlite
+
+import (
+	"database/sql"
+	"strings"
+
+	"github.com/gholt/kvt"
+)
+
+// Backend is a kvt.Backend storing each entry as a row. Deletion markers have
+// a NULL value. Deltas are merged straight into the table, keeping whichever
+// entry is newest, so Load never has deltas to replay.
+type Backend struct {
+	db    *sql.DB
+	table string
+}
+
+var _ kvt.Backend = &Backend{}
+
+// New returns a Backend using the table in db, creating the table if it
+// doesn't exist.
+func New(db *sql.DB, table string) (*Backend, error) {
+	backend := &Backend{db: db, table: `"` + strings.ReplaceAll(table, `"`, `""`) + `"`}
+	_, err := db.Exec(`CREATE TABLE IF NOT EXISTS ` + backend.table + ` (
+		key TEXT PRIMARY KEY NOT NULL,
+		value TEXT,
+		timestamp INTEGER NOT NULL,
+		flags INTEGER NOT NULL DEFAULT 0
+	)`)
+	if err != nil {
+		return nil, err
+	}
+	return backend, nil
+}
+
+// Load returns every entry in the table.
+func (backend *Backend) Load() (kvt.Store, error) {
+	rows, err := backend.db.Query(`SELECT key, value, timestamp, flags FROM ` + backend.table)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	store := kvt.Store{}
+	for rows.Next() {
+		var key string
+		var value sql.NullString
+		valueTimestamp := &kvt.ValueTimestamp{}
+		if err = rows.Scan(&key, &value, &valueTimestamp.Timestamp, &valueTimestamp.Flags); err != nil {
+			return nil, err
+		}
+		if value.Valid {
+			valueTimestamp.Value = &value.String
+		}
+		store[key] = valueTimestamp
+	}
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// Save replaces the table's rows with the store's entries in one transaction.
+func (backend *Backend) Save(store kvt.Store) error {
+	return backend.transaction(func(tx *sql.Tx) error {
+		if _, err := tx.Exec(`DELETE FROM ` + backend.table); err != nil {
+			return err
+		}
+		return backend.upsert(tx, store)
+	})
+}
+
+// AppendDelta merges the delta into the table in one transaction, keeping
+// whichever entry for each key has the newer timestamp.
+func (backend *Backend) AppendDelta(delta kvt.Store) error {
+	return backend.transaction(func(tx *sql.Tx) error {
+		return backend.upsert(tx, delta)
+	})
+}
+
+// Close does nothing; the caller remains responsible for closing the *sql.DB.
+func (backend *Backend) Close() error {
+	return nil
+}
+
+// transaction calls fn within a transaction, committing if fn returns nil and
+// rolling back otherwise.
+func (backend *Backend) transaction(fn func(tx *sql.Tx) error) error {
+	tx, err := backend.db.Begin()
+	if err != nil {
+		return err
+	}
+	if err = fn(tx); err != nil {
+		tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+// upsert writes the store's entries to the table, except where the table
+// already has a newer entry for a key.
+func (backend *Backend) upsert(tx *sql.Tx, store kvt.Store) error {
+	stmt, err := tx.Prepare(`INSERT INTO ` + backend.table + ` (key, value, timestamp, flags) VALUES (?, ?, ?, ?)
+		ON CONFLICT (key) DO UPDATE SET value = excluded.value, timestamp = excluded.timestamp, flags = excluded.flags
+		WHERE excluded.timestamp > ` + backend.table + `.timestamp`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+	for key, valueTimestamp := range store {
+		var value sql.NullString
+		if valueTimestamp.Value != nil {
+			value = sql.NullString{String: *valueTimestamp.Value, Valid: true}
+		}
+		if _, err = stmt.Exec(key, value, valueTimestamp.Timestamp, int64(valueTimestamp.Flags)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,200 @@
+package kvtsqlite_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/gholt/kvt"
+	"github.com/gholt/kvt/kvtsqlite"
+)
+
+// fakeRow is a row of the table held by fakeDriver.
+type fakeRow struct {
+	value     interface{}
+	timestamp int64
+	flags     int64
+}
+
+// fakeDriver is a database/sql driver understanding just the statements
+// Backend uses, with a single table shared by all its connections. It checks
+// the statements are as expected and applies the upsert's WHERE clause as
+// SQLite would.
+type fakeDriver struct {
+	lock  sync.Mutex
+	table string
+	rows  map[string]fakeRow
+}
+
+func (fake *fakeDriver) Open(name string) (driver.Conn, error) {
+	return &fakeConn{fake: fake}, nil
+}
+
+type fakeConn struct {
+	fake *fakeDriver
+	// saved is the table as of Begin, restored by Rollback.
+	saved map[string]fakeRow
+}
+
+func (conn *fakeConn) Prepare(query string) (driver.Stmt, error) {
+	return &fakeStmt{conn: conn, query: strings.Join(strings.Fields(query), " ")}, nil
+}
+
+func (conn *fakeConn) Close() error {
+	return nil
+}
+
+func (conn *fakeConn) Begin() (driver.Tx, error) {
+	conn.fake.lock.Lock()
+	defer conn.fake.lock.Unlock()
+	conn.saved = map[string]fakeRow{}
+	for key, row := range conn.fake.rows {
+		conn.saved[key] = row
+	}
+	return conn, nil
+}
+
+func (conn *fakeConn) Commit() error {
+	conn.saved = nil
+	return nil
+}
+
+func (conn *fakeConn) Rollback() error {
+	conn.fake.lock.Lock()
+	defer conn.fake.lock.Unlock()
+	conn.fake.rows = conn.saved
+	conn.saved = nil
+	return nil
+}
+
+type fakeStmt struct {
+	conn  *fakeConn
+	query string
+}
+
+func (stmt *fakeStmt) Close() error {
+	return nil
+}
+
+func (stmt *fakeStmt) NumInput() int {
+	return strings.Count(stmt.query, "?")
+}
+
+func (stmt *fakeStmt) Exec(args []driver.Value) (driver.Result, error) {
+	fake := stmt.conn.fake
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+	table := fake.table
+	switch stmt.query {
+	case `CREATE TABLE IF NOT EXISTS ` + table + ` ( key TEXT PRIMARY KEY NOT NULL, value TEXT, timestamp INTEGER NOT NULL, flags INTEGER NOT NULL DEFAULT 0 )`:
+		if fake.rows == nil {
+			fake.rows = map[string]fakeRow{}
+		}
+	case `DELETE FROM ` + table:
+		fake.rows = map[string]fakeRow{}
+	case `INSERT INTO ` + table + ` (key, value, timestamp, flags) VALUES (?, ?, ?, ?) ON CONFLICT (key) DO UPDATE SET value = excluded.value, timestamp = excluded.timestamp, flags = excluded.flags WHERE excluded.timestamp > ` + table + `.timestamp`:
+		key, ok := args[0].(string)
+		if !ok {
+			return nil, fmt.Errorf("key %#v is not a string", args[0])
+		}
+		if _, ok = args[1].(string); !ok && args[1] != nil {
+			return nil, fmt.Errorf("value %#v is neither a string nor NULL", args[1])
+		}
+		row := fakeRow{value: args[1], timestamp: args[2].(int64), flags: args[3].(int64)}
+		if existing, ok := fake.rows[key]; !ok || row.timestamp > existing.timestamp {
+			fake.rows[key] = row
+		}
+	default:
+		return nil, fmt.Errorf("unexpected statement %q", stmt.query)
+	}
+	return driver.RowsAffected(1), nil
+}
+
+func (stmt *fakeStmt) Query(args []driver.Value) (driver.Rows, error) {
+	fake := stmt.conn.fake
+	fake.lock.Lock()
+	defer fake.lock.Unlock()
+	if stmt.query != `SELECT key, value, timestamp, flags FROM `+fake.table {
+		return nil, fmt.Errorf("unexpected query %q", stmt.query)
+	}
+	rows := &fakeRows{}
+	for key, row := range fake.rows {
+		rows.values = append(rows.values, []driver.Value{key, row.value, row.timestamp, row.flags})
+	}
+	sort.Slice(rows.values, func(i, j int) bool { return rows.values[i][0].(string) < rows.values[j][0].(string) })
+	return rows, nil
+}
+
+type fakeRows struct {
+	values [][]driver.Value
+}
+
+func (rows *fakeRows) Columns() []string {
+	return []string{"key", "value", "timestamp", "flags"}
+}
+
+func (rows *fakeRows) Close() error {
+	return nil
+}
+
+func (rows *fakeRows) Next(dest []driver.Value) error {
+	if len(rows.values) == 0 {
+		return io.EOF
+	}
+	copy(dest, rows.values[0])
+	rows.values = rows.values[1:]
+	return nil
+}
+
+func TestBackend(t *testing.T) {
+	fake := &fakeDriver{table: `"kvt ""entries"""`}
+	sql.Register("kvtsqlite-fake", fake)
+	db, err := sql.Open("kvtsqlite-fake", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	backend, err := kvtsqlite.New(db, `kvt "entries"`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.SetTimestamped("B", "two", 2)
+	store.DeleteTimestamped("C", 3)
+	store["B"].Flags = kvt.FlagPinned
+	if err = backend.Save(store); err != nil {
+		t.Fatal(err)
+	}
+	if row := fake.rows["C"]; row.value != nil || row.timestamp != 3 {
+		t.Fatalf("deletion marker stored as %#v", row)
+	}
+	delta := kvt.Store{}
+	delta.SetTimestamped("A", "uno", 4)
+	delta.SetTimestamped("B", "stale", 1)
+	delta.SetTimestamped("C", "stale", 2)
+	delta.DeleteTimestamped("D", 5)
+	if err = backend.AppendDelta(delta); err != nil {
+		t.Fatal(err)
+	}
+	loaded, err := backend.Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := loaded.String(); s != `{"A":["uno",4],"B":["two",2,4],"C":[null,3],"D":[null,5]}` {
+		t.Fatal(s)
+	}
+	store2 := kvt.Store{}
+	store2.SetTimestamped("E", "five", 6)
+	if err = backend.Save(store2); err != nil {
+		t.Fatal(err)
+	}
+	if loaded, err = backend.Load(); err != nil || loaded.String() != `{"E":["five",6]}` {
+		t.Fatal(loaded, err)
+	}
+}
@@ -0,0 +1,30 @@
+package kvtsqlite_test
+
+import (
+	"database/sql"
+
+	"github.com/gholt/kvt"
+	"github.com/gholt/kvt/kvtsqlite"
+)
+
+func Example() {
+	// A SQLite driver, registered as "sqlite", must be imported.
+	db, err := sql.Open("sqlite", "store.db")
+	if err != nil {
+		panic(err)
+	}
+	defer db.Close()
+	backend, err := kvtsqlite.New(db, "kvt")
+	if err != nil {
+		panic(err)
+	}
+	syncStore := kvt.NewSyncStore(nil)
+	wal, err := syncStore.OpenWAL(backend)
+	if err != nil {
+		panic(err)
+	}
+	defer wal.Close()
+	// Each change is now written to the kvt table, where it can be queried:
+	//   SELECT key, value FROM kvt WHERE value IS NOT NULL ORDER BY timestamp DESC
+	syncStore.Set("A", "one")
+}
@@ -0,0 +1,121 @@
+// Package kvttest provides tests that any kvt.Storer implementation should
+// pass, and a mock kvt.Storer for testing code that uses one.
+package kvttest
+
+import (
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+// RunStorerTests checks that the Storer implementation made by factory
+// behaves the same as the reference kvt.Store: last write wins by timestamp,
+// deletes leave markers, Purge only discards old markers, and Hash matches
+// kvt.Store's for the same entries. factory must return a new, empty Storer
+// each time it is called.
+func RunStorerTests(t *testing.T, factory func() kvt.Storer) {
+	t.Run("LastWriteWins", func(t *testing.T) {
+		storer := factory()
+		storer.SetTimestamped("A", "one", 2)
+		storer.SetTimestamped("A", "older", 1)
+		storer.SetTimestamped("A", "same", 2)
+		if v := storer.Get("A"); v != "one" {
+			t.Errorf("Get after older and equal writes was %q, expected %q", v, "one")
+		}
+		storer.SetTimestamped("A", "newer", 3)
+		if v := storer.Get("A"); v != "newer" {
+			t.Errorf("Get after newer write was %q, expected %q", v, "newer")
+		}
+		if v := storer.Get("missing"); v != "" {
+			t.Errorf("Get of missing key was %q", v)
+		}
+	})
+	t.Run("Tombstones", func(t *testing.T) {
+		storer := factory()
+		storer.SetTimestamped("A", "one", 1)
+		storer.DeleteTimestamped("A", 2)
+		if v := storer.Get("A"); v != "" {
+			t.Errorf("Get after delete was %q", v)
+		}
+		storer.SetTimestamped("A", "older", 1)
+		if v := storer.Get("A"); v != "" {
+			t.Errorf("older write resurrected deleted key as %q", v)
+		}
+		storer.DeleteTimestamped("B", 5)
+		storer.SetTimestamped("B", "two", 4)
+		if v := storer.Get("B"); v != "" {
+			t.Errorf("write older than deletion marker gave %q", v)
+		}
+		snapshot := storer.Copy()
+		if snapshot["A"] == nil || snapshot["A"].Value != nil || snapshot["A"].Timestamp != 2 {
+			t.Errorf("expected a deletion marker at 2 for A, got %v", snapshot["A"])
+		}
+	})
+	t.Run("Purge", func(t *testing.T) {
+		storer := factory()
+		storer.SetTimestamped("live", "one", 1)
+		storer.DeleteTimestamped("old", 1)
+		storer.DeleteTimestamped("new", 5)
+		storer.Purge(3)
+		snapshot := storer.Copy()
+		if snapshot["live"] == nil {
+			t.Error("Purge discarded a live entry")
+		}
+		if snapshot["old"] != nil {
+			t.Error("Purge kept a deletion marker older than the cutoff")
+		}
+		if snapshot["new"] == nil {
+			t.Error("Purge discarded a deletion marker newer than the cutoff")
+		}
+	})
+	t.Run("Absorb", func(t *testing.T) {
+		storer := factory()
+		storer.SetTimestamped("A", "one", 2)
+		storer.SetTimestamped("B", "two", 1)
+		store2 := kvt.Store{}
+		store2.SetTimestamped("A", "older", 1)
+		store2.DeleteTimestamped("B", 2)
+		store2.SetTimestamped("C", "three", 1)
+		storer.Absorb(store2)
+		if s, expected := storer.Copy().String(), `{"A":["one",2],"B":[null,2],"C":["three",1]}`; s != expected {
+			t.Errorf("after Absorb got %s, expected %s", s, expected)
+		}
+	})
+	t.Run("ModifiedSince", func(t *testing.T) {
+		storer := factory()
+		storer.SetTimestamped("A", "one", 1)
+		storer.DeleteTimestamped("B", 2)
+		storer.SetTimestamped("C", "three", 3)
+		if s, expected := storer.ModifiedSince(1).String(), `{"B":[null,2],"C":["three",3]}`; s != expected {
+			t.Errorf("ModifiedSince(1) got %s, expected %s", s, expected)
+		}
+	})
+	t.Run("Copy", func(t *testing.T) {
+		storer := factory()
+		storer.SetTimestamped("A", "one", 1)
+		snapshot := storer.Copy()
+		snapshot.SetTimestamped("A", "changed", 2)
+		snapshot.SetTimestamped("B", "added", 2)
+		if v := storer.Get("A"); v != "one" {
+			t.Errorf("changing a Copy changed the Storer to %q", v)
+		}
+		if v := storer.Get("B"); v != "" {
+			t.Errorf("adding to a Copy added %q to the Storer", v)
+		}
+	})
+	t.Run("Hash", func(t *testing.T) {
+		storer := factory()
+		reference := kvt.Store{}
+		if storer.Hash() != reference.Hash() {
+			t.Error("Hash of empty Storer doesn't match empty Store")
+		}
+		for _, s := range []kvt.Storer{storer, reference} {
+			s.SetTimestamped("A", "one", 1)
+			s.DeleteTimestamped("B", 2)
+			s.SetTimestamped("C", "three", 3)
+		}
+		if a, b := storer.Hash(), reference.Hash(); a != b {
+			t.Errorf("Hash was %s, expected %s", a, b)
+		}
+	})
+}
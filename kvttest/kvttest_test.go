@@ -0,0 +1,21 @@
+package kvttest_test
+
+import (
+	"testing"
+
+	"github.com/gholt/kvt"
+	"github.com/gholt/kvt/kvttest"
+)
+
+func TestStorers(t *testing.T) {
+	for name, factory := range map[string]func() kvt.Storer{
+		"Store":        func() kvt.Storer { return kvt.Store{} },
+		"SyncStore":    func() kvt.Storer { return kvt.NewSyncStore(nil) },
+		"ShardedStore": func() kvt.Storer { return kvt.NewShardedStore(4) },
+		"AtomicStore":  func() kvt.Storer { return kvt.NewAtomicStore(nil) },
+		"MapStore":     func() kvt.Storer { return kvt.NewMapStore(nil) },
+		"StorerMock":   func() kvt.Storer { return kvttest.Wrap(kvt.Store{}) },
+	} {
+		t.Run(name, func(t *testing.T) { kvttest.RunStorerTests(t, factory) })
+	}
+}
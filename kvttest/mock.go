@@ -0,0 +1,130 @@
+package kvttest
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gholt/kvt"
+)
+
+// StorerMock is a kvt.Storer whose methods call the matching Func fields,
+// recording each call in Calls. Calling a method whose Func is nil panics, so
+// tests only need to set the methods they expect to be used.
+type StorerMock struct {
+	GetFunc               func(key string) string
+	SetFunc               func(key string, value string)
+	SetTimestampedFunc    func(key string, value string, timestamp int64)
+	DeleteFunc            func(key string)
+	DeleteTimestampedFunc func(key string, timestamp int64)
+	PurgeFunc             func(cutoff int64)
+	AbsorbFunc            func(store2 kvt.Store)
+	ModifiedSinceFunc     func(timestamp int64) kvt.Store
+	CopyFunc              func() kvt.Store
+	HashFunc              func() string
+
+	lock  sync.Mutex
+	calls []Call
+}
+
+// Call is a record of one method call made to a StorerMock.
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+var _ kvt.Storer = &StorerMock{}
+
+// Calls returns the calls made so far, in order.
+func (mock *StorerMock) Calls() []Call {
+	mock.lock.Lock()
+	defer mock.lock.Unlock()
+	return append([]Call(nil), mock.calls...)
+}
+
+// record notes the call and panics if fn is nil.
+func (mock *StorerMock) record(method string, fnIsNil bool, args ...interface{}) {
+	mock.lock.Lock()
+	mock.calls = append(mock.calls, Call{Method: method, Args: args})
+	mock.lock.Unlock()
+	if fnIsNil {
+		panic(fmt.Sprintf("kvttest: unexpected call to StorerMock.%s", method))
+	}
+}
+
+// Get calls GetFunc.
+func (mock *StorerMock) Get(key string) string {
+	mock.record("Get", mock.GetFunc == nil, key)
+	return mock.GetFunc(key)
+}
+
+// Set calls SetFunc.
+func (mock *StorerMock) Set(key string, value string) {
+	mock.record("Set", mock.SetFunc == nil, key, value)
+	mock.SetFunc(key, value)
+}
+
+// SetTimestamped calls SetTimestampedFunc.
+func (mock *StorerMock) SetTimestamped(key string, value string, timestamp int64) {
+	mock.record("SetTimestamped", mock.SetTimestampedFunc == nil, key, value, timestamp)
+	mock.SetTimestampedFunc(key, value, timestamp)
+}
+
+// Delete calls DeleteFunc.
+func (mock *StorerMock) Delete(key string) {
+	mock.record("Delete", mock.DeleteFunc == nil, key)
+	mock.DeleteFunc(key)
+}
+
+// DeleteTimestamped calls DeleteTimestampedFunc.
+func (mock *StorerMock) DeleteTimestamped(key string, timestamp int64) {
+	mock.record("DeleteTimestamped", mock.DeleteTimestampedFunc == nil, key, timestamp)
+	mock.DeleteTimestampedFunc(key, timestamp)
+}
+
+// Purge calls PurgeFunc.
+func (mock *StorerMock) Purge(cutoff int64) {
+	mock.record("Purge", mock.PurgeFunc == nil, cutoff)
+	mock.PurgeFunc(cutoff)
+}
+
+// Absorb calls AbsorbFunc.
+func (mock *StorerMock) Absorb(store2 kvt.Store) {
+	mock.record("Absorb", mock.AbsorbFunc == nil, store2)
+	mock.AbsorbFunc(store2)
+}
+
+// ModifiedSince calls ModifiedSinceFunc.
+func (mock *StorerMock) ModifiedSince(timestamp int64) kvt.Store {
+	mock.record("ModifiedSince", mock.ModifiedSinceFunc == nil, timestamp)
+	return mock.ModifiedSinceFunc(timestamp)
+}
+
+// Copy calls CopyFunc.
+func (mock *StorerMock) Copy() kvt.Store {
+	mock.record("Copy", mock.CopyFunc == nil)
+	return mock.CopyFunc()
+}
+
+// Hash calls HashFunc.
+func (mock *StorerMock) Hash() string {
+	mock.record("Hash", mock.HashFunc == nil)
+	return mock.HashFunc()
+}
+
+// Wrap returns a StorerMock whose Funcs all call through to storer, so a test
+// can record the calls made to a working Storer or override just a few
+// methods.
+func Wrap(storer kvt.Storer) *StorerMock {
+	return &StorerMock{
+		GetFunc:               storer.Get,
+		SetFunc:               storer.Set,
+		SetTimestampedFunc:    storer.SetTimestamped,
+		DeleteFunc:            storer.Delete,
+		DeleteTimestampedFunc: storer.DeleteTimestamped,
+		PurgeFunc:             storer.Purge,
+		AbsorbFunc:            storer.Absorb,
+		ModifiedSinceFunc:     storer.ModifiedSince,
+		CopyFunc:              storer.Copy,
+		HashFunc:              storer.Hash,
+	}
+}
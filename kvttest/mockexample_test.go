@@ -0,0 +1,28 @@
+package kvttest_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+	"github.com/gholt/kvt/kvttest"
+)
+
+func ExampleStorerMock() {
+	mock := &kvttest.StorerMock{
+		GetFunc: func(key string) string { return "value for " + key },
+	}
+	fmt.Println(mock.Get("A"))
+
+	// Wrap records calls to a real Storer.
+	mock = kvttest.Wrap(kvt.Store{})
+	mock.SetTimestamped("A", "one", 1)
+	mock.Get("A")
+	for _, call := range mock.Calls() {
+		fmt.Println(call.Method, call.Args)
+	}
+
+	// Output:
+	// value for A
+	// SetTimestamped [A one 1]
+	// Get [A]
+}
@@ -0,0 +1,105 @@
+package kvt
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// PurgeToLedger is like Purge but first appends each deletion marker being
+// discarded to w as a ledger line of the form: timestamp "key"
+//
+// Keeping such a ledger lets a store that has been away for longer than the
+// purge window still learn of deletions, by absorbing the store returned by
+// ReadLedger. If writing to w fails, nothing is purged.
+func (store Store) PurgeToLedger(cutoff int64, w io.Writer) error {
+	var ks []string
+	for _, k := range store.sortedKeys() {
+		valueTimestamp := store[k]
+		if valueTimestamp.purgeable(cutoff) {
+			ks = append(ks, k)
+		}
+	}
+	writer := bufio.NewWriter(w)
+	for _, k := range ks {
+		if _, err := fmt.Fprintf(writer, "%d %s\n", store[k].Timestamp, strconv.Quote(k)); err != nil {
+			return err
+		}
+	}
+	if err := writer.Flush(); err != nil {
+		return err
+	}
+	for _, k := range ks {
+		delete(store, k)
+	}
+	return nil
+}
+
+// PurgeToLedgerFile is like PurgeToLedger but appends to the ledger file at
+// path, creating it if needed, and syncs it before purging.
+func (store Store) PurgeToLedgerFile(cutoff int64, path string) error {
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0666)
+	if err != nil {
+		return err
+	}
+	ledger := &syncWriter{f: f}
+	err = store.PurgeToLedger(cutoff, ledger)
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
+
+// syncWriter syncs its file whenever written to, so PurgeToLedger won't purge
+// anything that isn't durably in the ledger.
+type syncWriter struct {
+	f *os.File
+}
+
+func (writer *syncWriter) Write(b []byte) (int, error) {
+	n, err := writer.f.Write(b)
+	if err == nil {
+		err = writer.f.Sync()
+	}
+	return n, err
+}
+
+// ReadLedger returns a store of the deletion markers recorded in the ledger
+// read from r, as written by PurgeToLedger.
+func ReadLedger(r io.Reader) (Store, error) {
+	store := Store{}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(nil, 1<<30)
+	for line := 1; scanner.Scan(); line++ {
+		parts := strings.SplitN(scanner.Text(), " ", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid ledger line %d", line)
+		}
+		timestamp, err := strconv.ParseInt(parts[0], 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timestamp on ledger line %d", line)
+		}
+		key, err := strconv.Unquote(parts[1])
+		if err != nil {
+			return nil, fmt.Errorf("invalid key on ledger line %d", line)
+		}
+		store.DeleteTimestamped(key, timestamp)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return store, nil
+}
+
+// LoadLedgerFile is like ReadLedger but reads the ledger file at path.
+func LoadLedgerFile(path string) (Store, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadLedger(f)
+}
@@ -0,0 +1,16 @@
+package kvt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestReadLedgerJunk(t *testing.T) {
+	for _, junk := range []string{"1\n", "x \"A\"\n", "1 A\n"} {
+		if _, err := kvt.ReadLedger(strings.NewReader(junk)); err == nil {
+			t.Fatal(junk)
+		}
+	}
+}
@@ -0,0 +1,64 @@
+package kvt_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_PurgeToLedger() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	store.DeleteTimestamped("C d", 3)
+	store.DeleteTimestamped("E", 10)
+	var ledger bytes.Buffer
+	fmt.Println(store.PurgeToLedger(5, &ledger))
+	fmt.Print(ledger.String())
+	fmt.Println(store)
+
+	// Output:
+	// <nil>
+	// 2 "B"
+	// 3 "C d"
+	// {"A":["one",1],"E":[null,10]}
+}
+
+func ExampleReadLedger() {
+	// A store that was offline for a long time still has B.
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.SetTimestamped("B", "two", 1)
+	tombstones, err := kvt.ReadLedger(strings.NewReader("2 \"B\"\n3 \"C d\"\n"))
+	fmt.Println(tombstones, err)
+	store.Absorb(tombstones)
+	fmt.Println(store.SimpleString())
+
+	// Output:
+	// {"B":[null,2],"C d":[null,3]} <nil>
+	// A=one,B/deleted,C d/deleted
+}
+
+func ExampleLoadLedgerFile() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "ledger")
+	store := kvt.Store{}
+	store.DeleteTimestamped("A", 1)
+	fmt.Println(store.PurgeToLedgerFile(5, path))
+	store.DeleteTimestamped("B", 6)
+	fmt.Println(store.PurgeToLedgerFile(10, path))
+	fmt.Println(kvt.LoadLedgerFile(path))
+
+	// Output:
+	// <nil>
+	// <nil>
+	// {"A":[null,1],"B":[null,6]} <nil>
+}
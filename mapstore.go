@@ -0,0 +1,138 @@
+package kvt
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// MapStore is backed by a sync.Map, which suits workloads where many
+// goroutines mostly touch disjoint keys: they don't contend on a shared lock
+// as they would with SyncStore. Each entry is replaced rather than modified in
+// place, so readers never see partial updates. Methods covering the whole
+// store, such as Hash and Copy, may not see a single point in time if there
+// are concurrent writers. The zero value is an empty MapStore ready for use.
+type MapStore struct {
+	entries sync.Map
+}
+
+// NewMapStore returns a MapStore starting with copies of the entries in
+// store, which may be nil.
+func NewMapStore(store Store) *MapStore {
+	mapStore := &MapStore{}
+	mapStore.Absorb(store)
+	return mapStore
+}
+
+// load returns the entry for key, which must not be modified, or nil.
+func (mapStore *MapStore) load(key string) *ValueTimestamp {
+	valueTimestamp, _ := mapStore.entries.Load(key)
+	v, _ := valueTimestamp.(*ValueTimestamp)
+	return v
+}
+
+// put stores valueTimestamp2, which must not be modified afterwards, for the
+// key unless there is already an entry at least as new.
+func (mapStore *MapStore) put(key string, valueTimestamp2 *ValueTimestamp) {
+	for {
+		old, loaded := mapStore.entries.LoadOrStore(key, valueTimestamp2)
+		if !loaded || old.(*ValueTimestamp).Timestamp >= valueTimestamp2.Timestamp {
+			return
+		}
+		if mapStore.entries.CompareAndSwap(key, old, valueTimestamp2) {
+			return
+		}
+	}
+}
+
+// Get returns the value for a key; see Store.Get.
+func (mapStore *MapStore) Get(key string) string {
+	valueTimestamp := mapStore.load(key)
+	if valueTimestamp == nil || valueTimestamp.Value == nil {
+		return ""
+	}
+	return *valueTimestamp.Value
+}
+
+// Set is equivalent to SetTimestamped(key, value, Now()).
+func (mapStore *MapStore) Set(key string, value string) {
+	mapStore.SetTimestamped(key, value, Now())
+}
+
+// SetTimestamped stores the value for the key unless there is a newer entry;
+// see Store.SetTimestamped.
+func (mapStore *MapStore) SetTimestamped(key string, value string, timestamp int64) {
+	mapStore.put(key, &ValueTimestamp{Value: &value, Timestamp: timestamp})
+}
+
+// Delete is equivalent to DeleteTimestamped(key, Now()).
+func (mapStore *MapStore) Delete(key string) {
+	mapStore.DeleteTimestamped(key, Now())
+}
+
+// DeleteTimestamped records a deletion marker for the key unless there is a
+// newer entry; see Store.DeleteTimestamped.
+func (mapStore *MapStore) DeleteTimestamped(key string, timestamp int64) {
+	mapStore.put(key, &ValueTimestamp{Timestamp: timestamp})
+}
+
+// Purge discards deletion markers older than the cutoff; see Store.Purge.
+func (mapStore *MapStore) Purge(cutoff int64) {
+	mapStore.entries.Range(func(key, value interface{}) bool {
+		valueTimestamp := value.(*ValueTimestamp)
+		if valueTimestamp.purgeable(cutoff) {
+			mapStore.entries.CompareAndDelete(key, value)
+		}
+		return true
+	})
+}
+
+// Absorb updates mapStore with copies of any newer entries from store2; see
+// Store.Absorb.
+func (mapStore *MapStore) Absorb(store2 Store) {
+	for key, valueTimestamp2 := range store2 {
+		valueTimestampCopy := *valueTimestamp2
+		mapStore.put(key, &valueTimestampCopy)
+	}
+}
+
+// ModifiedSince returns a new Store of the entries newer than the timestamp;
+// see Store.ModifiedSince.
+func (mapStore *MapStore) ModifiedSince(timestamp int64) Store {
+	store2 := Store{}
+	mapStore.entries.Range(func(key, value interface{}) bool {
+		if valueTimestamp := value.(*ValueTimestamp); valueTimestamp.Timestamp > timestamp {
+			valueTimestampCopy := *valueTimestamp
+			store2[key.(string)] = &valueTimestampCopy
+		}
+		return true
+	})
+	return store2
+}
+
+// Copy returns a new Store holding copies of all the entries; see Store.Copy.
+func (mapStore *MapStore) Copy() Store {
+	store2 := Store{}
+	mapStore.entries.Range(func(key, value interface{}) bool {
+		valueTimestampCopy := *value.(*ValueTimestamp)
+		store2[key.(string)] = &valueTimestampCopy
+		return true
+	})
+	return store2
+}
+
+// Hash returns a hash of the keys, timestamps, and flags, identical to what
+// Store.Hash would return for the same entries.
+func (mapStore *MapStore) Hash() string {
+	return mapStore.Copy().Hash()
+}
+
+// MarshalJSON returns the JSON encoded version of the store or an error.
+func (mapStore *MapStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(mapStore.Copy())
+}
+
+// String returns the JSON encoded string representation of the store; see
+// Store.String.
+func (mapStore *MapStore) String() string {
+	return mapStore.Copy().String()
+}
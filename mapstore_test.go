@@ -0,0 +1,39 @@
+package kvt_test
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestMapStoreConcurrentWrites(t *testing.T) {
+	mapStore := kvt.NewMapStore(nil)
+	expected := kvt.Store{}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		for j := 0; j < 20; j++ {
+			key, value, timestamp := fmt.Sprintf("k%d", j), fmt.Sprintf("v%d", i), int64(1+(i*j)%11)
+			expected.SetTimestamped(key, value, timestamp)
+		}
+		wg.Add(1)
+		go func(i int) {
+			for j := 0; j < 20; j++ {
+				mapStore.SetTimestamped(fmt.Sprintf("k%d", j), fmt.Sprintf("v%d", i), int64(1+(i*j)%11))
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	// Entries with equal timestamps may differ in value depending on which
+	// goroutine won, so only the hashes of keys and timestamps are compared.
+	if a, b := mapStore.Hash(), expected.Hash(); a != b {
+		t.Fatal(a, b)
+	}
+	mapStore.DeleteTimestamped("k0", 100)
+	mapStore.Purge(101)
+	if mapStore.Copy()["k0"] != nil || len(mapStore.Copy()) != 19 {
+		t.Fatal(mapStore)
+	}
+}
@@ -0,0 +1,261 @@
+package kvt
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+)
+
+// errMsgpackTruncated is returned by UnmarshalMsgpack when the data ends
+// early.
+var errMsgpackTruncated = errors.New("kvt: truncated msgpack store")
+
+// MarshalMsgpack returns the store encoded as MessagePack, for exchanging
+// stores with services in languages where that is the standard. The shape
+// is the same as the JSON encoding: a map of each key, in key order, to an
+// array of [value, timestamp], or [value, timestamp, flags] if any Flags are
+// set, with a nil value for deletion markers. Integers use their smallest
+// encoding.
+//
+// The method names match the Marshaler and Unmarshaler interfaces of the
+// github.com/vmihailenco/msgpack package, so a Store within a larger
+// document encoded with it uses this encoding.
+func (store Store) MarshalMsgpack() ([]byte, error) {
+	b := appendMsgpackHeader(nil, 0x80, 0xde, 0xdf, 16, len(store))
+	for _, key := range store.sortedKeys() {
+		valueTimestamp := store[key]
+		b = appendMsgpackString(b, key)
+		if valueTimestamp.Flags != 0 {
+			b = append(b, 0x93)
+		} else {
+			b = append(b, 0x92)
+		}
+		if valueTimestamp.Value == nil {
+			b = append(b, 0xc0)
+		} else {
+			b = appendMsgpackString(b, *valueTimestamp.Value)
+		}
+		b = appendMsgpackInt(b, valueTimestamp.Timestamp)
+		if valueTimestamp.Flags != 0 {
+			b = appendMsgpackInt(b, int64(valueTimestamp.Flags))
+		}
+	}
+	return b, nil
+}
+
+// appendMsgpackHeader appends the header of a map or string of length n: the
+// fix form if n is less than fixLimit, otherwise the 16 or 32 bit form.
+func appendMsgpackHeader(b []byte, fix byte, code16 byte, code32 byte, fixLimit int, n int) []byte {
+	switch {
+	case n < fixLimit:
+		return append(b, fix|byte(n))
+	case n <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, code16), uint16(n))
+	default:
+		return binary.BigEndian.AppendUint32(append(b, code32), uint32(n))
+	}
+}
+
+func appendMsgpackString(b []byte, s string) []byte {
+	if len(s) >= 32 && len(s) <= math.MaxUint8 {
+		b = append(b, 0xd9, byte(len(s)))
+	} else {
+		b = appendMsgpackHeader(b, 0xa0, 0xda, 0xdb, 32, len(s))
+	}
+	return append(b, s...)
+}
+
+func appendMsgpackInt(b []byte, i int64) []byte {
+	switch {
+	case i >= 0 && i <= math.MaxInt8:
+		return append(b, byte(i))
+	case i < 0 && i >= -32:
+		return append(b, byte(i))
+	case i >= 0 && i <= math.MaxUint8:
+		return append(b, 0xcc, byte(i))
+	case i >= 0 && i <= math.MaxUint16:
+		return binary.BigEndian.AppendUint16(append(b, 0xcd), uint16(i))
+	case i >= 0 && i <= math.MaxUint32:
+		return binary.BigEndian.AppendUint32(append(b, 0xce), uint32(i))
+	case i >= math.MinInt8 && i <= math.MaxInt8:
+		return append(b, 0xd0, byte(i))
+	case i >= math.MinInt16 && i <= math.MaxInt16:
+		return binary.BigEndian.AppendUint16(append(b, 0xd1), uint16(i))
+	case i >= math.MinInt32 && i <= math.MaxInt32:
+		return binary.BigEndian.AppendUint32(append(b, 0xd2), uint32(i))
+	default:
+		return binary.BigEndian.AppendUint64(append(b, 0xd3), uint64(i))
+	}
+}
+
+// UnmarshalMsgpack replaces the store with the one encoded in b by
+// MarshalMsgpack. Any integer encoding is accepted, as are binary rather than
+// string values, as other MessagePack libraries may choose differently.
+func (store *Store) UnmarshalMsgpack(b []byte) error {
+	reader := &msgpackReader{b: b}
+	count, err := reader.length(0x80, 0xde, 0xdf, 16)
+	if err != nil {
+		return fmt.Errorf("kvt: expected msgpack map: %s", err)
+	}
+	if count > len(reader.b) {
+		return errMsgpackTruncated
+	}
+	store2 := make(Store, count)
+	for i := 0; i < count; i++ {
+		key, err := reader.str()
+		if err != nil {
+			return fmt.Errorf("kvt: invalid msgpack key: %s", err)
+		}
+		valueTimestamp, err := reader.valueTimestamp()
+		if err != nil {
+			return fmt.Errorf("kvt: invalid msgpack entry for %q: %s", key, err)
+		}
+		store2[key] = valueTimestamp
+	}
+	if len(reader.b) != 0 {
+		return fmt.Errorf("kvt: %d extra bytes after msgpack store", len(reader.b))
+	}
+	*store = store2
+	return nil
+}
+
+// msgpackReader decodes the parts of a MessagePack store in turn.
+type msgpackReader struct {
+	b []byte
+}
+
+// next returns the next n bytes.
+func (reader *msgpackReader) next(n int) ([]byte, error) {
+	if n > len(reader.b) {
+		return nil, errMsgpackTruncated
+	}
+	b := reader.b[:n]
+	reader.b = reader.b[n:]
+	return b, nil
+}
+
+// uint returns the next big endian unsigned integer of size bytes.
+func (reader *msgpackReader) uint(size int) (uint64, error) {
+	b, err := reader.next(size)
+	if err != nil {
+		return 0, err
+	}
+	var v uint64
+	for _, c := range b {
+		v = v<<8 | uint64(c)
+	}
+	return v, nil
+}
+
+// length returns the length from the header of a map or array; see
+// appendMsgpackHeader.
+func (reader *msgpackReader) length(fix byte, code16 byte, code32 byte, fixLimit int) (int, error) {
+	b, err := reader.next(1)
+	if err != nil {
+		return 0, err
+	}
+	var n uint64
+	switch {
+	case b[0]&^byte(fixLimit-1) == fix:
+		return int(b[0] & byte(fixLimit-1)), nil
+	case b[0] == code16:
+		n, err = reader.uint(2)
+	case b[0] == code32:
+		n, err = reader.uint(4)
+	default:
+		return 0, fmt.Errorf("unexpected type 0x%02x", b[0])
+	}
+	return int(n), err
+}
+
+// str returns the next string, or binary, as a string.
+func (reader *msgpackReader) str() (string, error) {
+	b, err := reader.next(1)
+	if err != nil {
+		return "", err
+	}
+	var n uint64
+	switch c := b[0]; {
+	case c&0xe0 == 0xa0:
+		n = uint64(c & 0x1f)
+	case c == 0xd9 || c == 0xc4:
+		n, err = reader.uint(1)
+	case c == 0xda || c == 0xc5:
+		n, err = reader.uint(2)
+	case c == 0xdb || c == 0xc6:
+		n, err = reader.uint(4)
+	default:
+		return "", fmt.Errorf("expected string, not type 0x%02x", c)
+	}
+	if err != nil {
+		return "", err
+	}
+	b, err = reader.next(int(n))
+	return string(b), err
+}
+
+// int returns the next integer.
+func (reader *msgpackReader) int() (int64, error) {
+	b, err := reader.next(1)
+	if err != nil {
+		return 0, err
+	}
+	c := b[0]
+	switch {
+	case c <= 0x7f:
+		return int64(c), nil
+	case c >= 0xe0:
+		return int64(int8(c)), nil
+	case c >= 0xcc && c <= 0xcf:
+		v, err := reader.uint(1 << (c - 0xcc))
+		if err == nil && v > math.MaxInt64 {
+			err = errors.New("integer out of range")
+		}
+		return int64(v), err
+	case c >= 0xd0 && c <= 0xd3:
+		size := 1 << (c - 0xd0)
+		v, err := reader.uint(size)
+		// Sign extend from size bytes.
+		shift := 64 - 8*size
+		return int64(v<<shift) >> shift, err
+	default:
+		return 0, fmt.Errorf("expected integer, not type 0x%02x", c)
+	}
+}
+
+// valueTimestamp returns the next [value, timestamp] or [value, timestamp,
+// flags] array.
+func (reader *msgpackReader) valueTimestamp() (*ValueTimestamp, error) {
+	n, err := reader.length(0x90, 0xdc, 0xdd, 16)
+	if err != nil {
+		return nil, err
+	}
+	if n != 2 && n != 3 {
+		return nil, errors.New("expected [value, timestamp] or [value, timestamp, flags]")
+	}
+	valueTimestamp := &ValueTimestamp{}
+	if len(reader.b) > 0 && reader.b[0] == 0xc0 {
+		reader.b = reader.b[1:]
+	} else {
+		value, err := reader.str()
+		if err != nil {
+			return nil, err
+		}
+		valueTimestamp.Value = &value
+	}
+	if valueTimestamp.Timestamp, err = reader.int(); err != nil {
+		return nil, err
+	}
+	if n == 3 {
+		flags, err := reader.int()
+		if err != nil {
+			return nil, err
+		}
+		if flags < 0 || flags > math.MaxUint32 {
+			return nil, errors.New("flags out of range")
+		}
+		valueTimestamp.Flags = Flags(flags)
+	}
+	return valueTimestamp, nil
+}
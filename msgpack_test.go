@@ -0,0 +1,67 @@
+package kvt_test
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	store := kvt.Store{}
+	for i, timestamp := range []int64{0, 1, 127, 128, 255, 256, 65535, 65536, math.MaxUint32, math.MaxUint32 + 1, math.MaxInt64, -1, -32, -33, -128, -129, -32768, -32769, math.MinInt32, math.MinInt32 - 1, math.MinInt64} {
+		store.SetTimestamped(strings.Repeat("k", i*5), strings.Repeat("v", i*i*i), timestamp)
+	}
+	store.SetTimestamped("long", strings.Repeat("v", 70000), 1)
+	store.DeleteTimestamped("deleted", 2)
+	store["deleted"].Flags = kvt.FlagPinned
+	store["long"].Flags = kvt.Flags(math.MaxUint32)
+	for i := 0; i < 20; i++ {
+		store.SetTimestamped(string(rune('a'+i)), "", int64(i))
+	}
+	b, err := store.MarshalMsgpack()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var loaded kvt.Store
+	if err = loaded.UnmarshalMsgpack(b); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := loaded.String(), store.String(); a != b {
+		t.Fatal(a, b)
+	}
+	for i := 0; i < len(b); i += 97 {
+		if err = loaded.UnmarshalMsgpack(b[:i]); err == nil {
+			t.Fatal("no error truncated to", i, "of", len(b))
+		}
+	}
+}
+
+func TestUnmarshalMsgpackOtherEncodings(t *testing.T) {
+	// {"A": [bin8 "one", uint64 1], "B": [nil, int64 -2, uint16 4]}
+	b := []byte{0xde, 0, 2,
+		0xa1, 'A', 0xdc, 0, 2, 0xc4, 3, 'o', 'n', 'e', 0xcf, 0, 0, 0, 0, 0, 0, 0, 1,
+		0xd9, 1, 'B', 0x93, 0xc0, 0xd3, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfe, 0xcd, 0, 4,
+	}
+	var store kvt.Store
+	if err := store.UnmarshalMsgpack(b); err != nil {
+		t.Fatal(err)
+	}
+	if a, b := store.String(), `{"A":["one",1],"B":[null,-2,4]}`; a != b {
+		t.Fatal(a, b)
+	}
+	for _, junk := range [][]byte{
+		{},
+		{0x90},
+		append(append([]byte{}, b...), 0),
+		{0x81, 0xa1, 'A', 0x91, 0xc0},
+		{0x81, 0xa1, 'A', 0x92, 0x01, 0x01},
+		{0x81, 0xa1, 'A', 0x92, 0xc0, 0xcf, 0x80, 0, 0, 0, 0, 0, 0, 0},
+		{0x81, 0xa1, 'A', 0x93, 0xc0, 0x01, 0xff},
+	} {
+		if err := store.UnmarshalMsgpack(junk); err == nil {
+			t.Errorf("expected error from %x", junk)
+		}
+	}
+}
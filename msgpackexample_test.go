@@ -0,0 +1,24 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_MarshalMsgpack() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	b, err := store.MarshalMsgpack()
+	fmt.Printf("% x %v\n", b, err)
+
+	var store2 kvt.Store
+	fmt.Println(store2.UnmarshalMsgpack(b))
+	fmt.Println(store2)
+
+	// Output:
+	// 82 a1 41 92 a3 6f 6e 65 01 a1 42 92 c0 02 <nil>
+	// <nil>
+	// {"A":["one",1],"B":[null,2]}
+}
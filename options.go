@@ -0,0 +1,97 @@
+package kvt
+
+import "time"
+
+// Option configures a store created with New or NewStorer.
+type Option func(*options)
+
+type options struct {
+	capacity int
+	syncMap  bool
+}
+
+// FileBackendOption configures a FileBackend created with NewFileBackend.
+type FileBackendOption func(*fileBackendOptions)
+
+type fileBackendOptions struct {
+	syncMode     SyncMode
+	syncInterval time.Duration
+}
+
+// SyncMode controls when a FileBackend fsyncs its journal, trading
+// durability for write latency.
+type SyncMode int
+
+const (
+	// SyncEveryWrite syncs after every append, so a write is durable before
+	// it returns. This is the default.
+	SyncEveryWrite SyncMode = iota
+	// SyncOnInterval syncs at most once per interval, as set with
+	// WithSyncInterval; a crash may lose up to an interval of writes.
+	SyncOnInterval
+	// SyncOnClose only syncs when the Backend is closed or saved; a crash
+	// may lose any writes since.
+	SyncOnClose
+)
+
+// WithSync sets when a FileBackend syncs its journal. Saves of the whole
+// store are always synced, as that's needed to replace the file atomically.
+func WithSync(mode SyncMode) FileBackendOption {
+	return func(opts *fileBackendOptions) {
+		opts.syncMode = mode
+	}
+}
+
+// WithSyncInterval is WithSync(SyncOnInterval) with the interval given; an
+// interval of 0 or less is treated as one second.
+func WithSyncInterval(interval time.Duration) FileBackendOption {
+	return func(opts *fileBackendOptions) {
+		opts.syncMode = SyncOnInterval
+		opts.syncInterval = interval
+	}
+}
+
+// WithCapacity sets how many keys the store should have room for initially;
+// a capacity less than 0 is treated as 0.
+func WithCapacity(capacity int) Option {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return func(opts *options) {
+		opts.capacity = capacity
+	}
+}
+
+// WithSyncMap has NewStorer return a MapStore, backed by a sync.Map, rather
+// than a SyncStore. It has no effect on New.
+func WithSyncMap() Option {
+	return func(opts *options) {
+		opts.syncMap = true
+	}
+}
+
+// New returns a new, empty store configured by the options given. The zero
+// Store{} literal is equivalent to New with no options.
+func New(opts ...Option) Store {
+	o := newOptions(opts)
+	return make(Store, o.capacity)
+}
+
+// NewStorer returns a new, empty store safe for concurrent use, configured by
+// the options given. By default it is a SyncStore.
+func NewStorer(opts ...Option) Storer {
+	o := newOptions(opts)
+	if o.syncMap {
+		return &MapStore{}
+	}
+	return NewSyncStore(make(Store, o.capacity))
+}
+
+// newOptions returns the options resulting from applying each of opts.
+func newOptions(opts []Option) *options {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
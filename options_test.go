@@ -0,0 +1,16 @@
+package kvt_test
+
+import (
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestWithCapacityNegative(t *testing.T) {
+	if store := kvt.New(kvt.WithCapacity(-1)); len(store) != 0 {
+		t.Fatal(store)
+	}
+	if storer := kvt.NewStorer(kvt.WithCapacity(-1)); storer.Get("A") != "" {
+		t.Fatal(storer)
+	}
+}
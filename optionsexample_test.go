@@ -0,0 +1,27 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleNew() {
+	store := kvt.New(kvt.WithCapacity(1000))
+	store.SetTimestamped("A", "one", 1)
+	fmt.Println(store)
+
+	// Output:
+	// {"A":["one",1]}
+}
+
+func ExampleNewStorer() {
+	storer := kvt.NewStorer(kvt.WithSyncMap())
+	storer.SetTimestamped("A", "one", 1)
+	storer.SetTimestamped("A", "old", 0)
+	_, isMapStore := storer.(*kvt.MapStore)
+	fmt.Println(storer.Get("A"), isMapStore)
+
+	// Output:
+	// one true
+}
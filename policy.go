@@ -0,0 +1,83 @@
+package kvt
+
+import "fmt"
+
+// Decision is what an AbsorbPolicy decides to do with an incoming entry.
+type Decision int
+
+const (
+	// Accept absorbs the entry as Absorb would.
+	Accept Decision = iota
+	// Reject discards the entry.
+	Reject
+	// Defer leaves the entry out for now but returns it in
+	// AbsorbStats.Deferred so it may be tried again later.
+	Defer
+)
+
+// String returns the name of the decision.
+func (decision Decision) String() string {
+	switch decision {
+	case Accept:
+		return "Accept"
+	case Reject:
+		return "Reject"
+	case Defer:
+		return "Defer"
+	}
+	return fmt.Sprintf("Decision(%d)", int(decision))
+}
+
+// Reason is a short, operator defined code explaining a Decision, such as
+// "readonly-prefix" or "too-large"; counts by reason are kept in AbsorbStats.
+type Reason string
+
+// AbsorbPolicy decides whether the remote entry for key may replace the local
+// one, which is nil if there isn't one. It is only consulted for entries that
+// Absorb would apply, that is, when remote is newer than local. Neither entry
+// may be modified.
+type AbsorbPolicy func(key string, local *ValueTimestamp, remote *ValueTimestamp) (Decision, Reason)
+
+// AbsorbStats reports what AbsorbWithPolicy did.
+type AbsorbStats struct {
+	Accepted int
+	Rejected int
+	// Deferred holds the entries the policy deferred.
+	Deferred Store
+	// Reasons counts the decisions by their reasons; an empty Reason is
+	// counted too.
+	Reasons map[Reason]int
+}
+
+// AbsorbWithPolicy is like Absorb but asks policy about each entry that would
+// be applied, only absorbing those it accepts.
+func (store Store) AbsorbWithPolicy(store2 Store, policy AbsorbPolicy) *AbsorbStats {
+	stats := &AbsorbStats{Deferred: Store{}, Reasons: map[Reason]int{}}
+	for key, valueTimestamp2 := range store2 {
+		valueTimestamp := store[key]
+		if valueTimestamp != nil && valueTimestamp.Timestamp >= valueTimestamp2.Timestamp {
+			continue
+		}
+		decision, reason := policy(key, valueTimestamp, valueTimestamp2)
+		stats.Reasons[reason]++
+		switch decision {
+		case Accept:
+			store[key] = valueTimestamp2
+			stats.Accepted++
+		case Defer:
+			stats.Deferred[key] = valueTimestamp2
+		default:
+			stats.Rejected++
+		}
+	}
+	return stats
+}
+
+// AbsorbWithPolicy is like Absorb but asks policy about each entry; see
+// Store.AbsorbWithPolicy. The policy is called with the write lock held, so
+// it must not use syncStore.
+func (syncStore *SyncStore) AbsorbWithPolicy(store2 Store, policy AbsorbPolicy) *AbsorbStats {
+	var stats *AbsorbStats
+	syncStore.absorb(store2, nil, func(store Store) { stats = store.AbsorbWithPolicy(store2, policy) })
+	return stats
+}
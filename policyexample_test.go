@@ -0,0 +1,38 @@
+package kvt_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_AbsorbWithPolicy() {
+	store := kvt.Store{}
+	store.SetTimestamped("local/A", "mine", 1)
+	store.SetTimestamped("shared/A", "one", 1)
+
+	store2 := kvt.Store{}
+	store2.SetTimestamped("local/A", "theirs", 2)
+	store2.SetTimestamped("shared/A", "uno", 2)
+	store2.SetTimestamped("shared/B", strings.Repeat("x", 100), 2)
+	store2.SetTimestamped("shared/C", "three", 0)
+
+	stats := store.AbsorbWithPolicy(store2, func(key string, local, remote *kvt.ValueTimestamp) (kvt.Decision, kvt.Reason) {
+		switch {
+		case strings.HasPrefix(key, "local/"):
+			return kvt.Reject, "local-only"
+		case remote.Value != nil && len(*remote.Value) > 10:
+			return kvt.Defer, "too-large"
+		}
+		return kvt.Accept, ""
+	})
+	fmt.Println(store.SimpleString())
+	fmt.Println(stats.Accepted, stats.Rejected, stats.Deferred.PrefixKeys(""))
+	fmt.Println(stats.Reasons)
+
+	// Output:
+	// local/A=mine,shared/A=uno,shared/C=three
+	// 2 1 [shared/B]
+	// map[:2 local-only:1 too-large:1]
+}
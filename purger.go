@@ -0,0 +1,52 @@
+package kvt
+
+import "time"
+
+// PurgeInfo records how a SyncStore has been purged, so that it can be saved
+// with the store; see SyncStore.SaveFile.
+//
+// Restoring it only keeps deletion markers that were purged before the save
+// from coming back with the saved store. It can't stop a restored store from
+// absorbing old entries for keys whose deletion markers it, or its peers,
+// already purged, as nothing is left to tell those keys apart from ones that
+// were never deleted; only a retention longer than the longest time between
+// merges, as described for StartPurger, prevents that.
+//
+// Only SyncStore.SaveFile and LoadFile persist PurgeInfo. A WAL, AutoSaver or
+// other Backend saves just the store; save PurgeInfo alongside it separately
+// and Purge with its Cutoff again after loading.
+type PurgeInfo struct {
+	// Cutoff is the highest cutoff passed to Purge; deletion markers older
+	// than it have been discarded.
+	Cutoff int64 `json:"cutoff"`
+	// Retention is the retention of the most recently started Purger, or 0.
+	Retention time.Duration `json:"retention"`
+}
+
+// Purger periodically purges old deletion markers from a SyncStore; see
+// SyncStore.StartPurger.
+type Purger struct {
+	*background
+}
+
+// StartPurger starts a Purger that, every interval, discards deletion markers
+// older than retention. The retention should be longer than the longest time
+// any store that this one is merged with may go without a merge, otherwise
+// purged keys may be resurrected by the older data. An interval of 0 or less
+// is treated as one second. Call Stop or Close on the Purger when it is no
+// longer needed.
+func (syncStore *SyncStore) StartPurger(interval time.Duration, retention time.Duration) *Purger {
+	syncStore.lock.Lock()
+	syncStore.purgeInfo.Retention = retention
+	syncStore.lock.Unlock()
+	return &Purger{startBackground(interval, func() {
+		syncStore.Purge(Now() - int64(retention))
+	})}
+}
+
+// Close is the same as Stop, always returning nil; it lets a Purger be used
+// as an io.Closer.
+func (purger *Purger) Close() error {
+	purger.Stop()
+	return nil
+}
@@ -0,0 +1,24 @@
+package kvt_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore_StartPurger() {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.Set("A", "one")
+	syncStore.DeleteTimestamped("B", kvt.Timestamp(time.Now().Add(-time.Hour)))
+	syncStore.Delete("C")
+	purger := syncStore.StartPurger(time.Millisecond, time.Minute)
+	for len(syncStore.Tombstones()) > 1 {
+		time.Sleep(time.Millisecond)
+	}
+	purger.Close()
+	fmt.Println(syncStore.SimpleString())
+
+	// Output:
+	// A=one,C/deleted
+}
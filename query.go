@@ -0,0 +1,152 @@
+package kvt
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Query selects entries by key, value, timestamp, and whether they are
+// deletion markers. The zero value matches every entry. Queries are usually
+// made with ParseQuery.
+type Query struct {
+	// Prefix, if not empty, is required of each key.
+	Prefix string
+	// Contains, if not empty, is required within each value; deletion markers
+	// never match.
+	Contains string
+	// Regexp, if not nil, must match each value; deletion markers never match.
+	Regexp *regexp.Regexp
+	// Before, if not zero, requires timestamps older than it.
+	Before int64
+	// After, if not zero, requires timestamps newer than it.
+	After int64
+	// Deleted, if not nil, requires deletion markers when true or entries
+	// with values when false.
+	Deleted *bool
+}
+
+// ParseQuery parses a query of space separated name:value terms, such as:
+//
+//	prefix:config/ contains:"a b" regex:^v[0-9]+$ after:2017-01-02T03:04:05Z deleted:false
+//
+// Values may be Go quoted strings when they contain spaces. Timestamps for
+// before and after may be either nanoseconds since the Unix epoch or RFC 3339
+// times. An empty query matches every entry.
+func ParseQuery(s string) (*Query, error) {
+	query := &Query{}
+	for {
+		s = strings.TrimLeft(s, " \t\n")
+		if s == "" {
+			return query, nil
+		}
+		i := strings.IndexByte(s, ':')
+		if i < 0 {
+			return nil, fmt.Errorf("expected name:value from: %s", s)
+		}
+		name := s[:i]
+		s = s[i+1:]
+		var value string
+		if strings.HasPrefix(s, `"`) {
+			quoted, err := strconv.QuotedPrefix(s)
+			if err != nil {
+				return nil, fmt.Errorf("invalid quoted value from: %s", s)
+			}
+			value, _ = strconv.Unquote(quoted)
+			s = s[len(quoted):]
+		} else {
+			i = strings.IndexAny(s, " \t\n")
+			if i < 0 {
+				i = len(s)
+			}
+			value = s[:i]
+			s = s[i:]
+		}
+		var err error
+		switch name {
+		case "prefix":
+			query.Prefix = value
+		case "contains":
+			query.Contains = value
+		case "regex":
+			query.Regexp, err = regexp.Compile(value)
+		case "before":
+			query.Before, err = parseQueryTimestamp(value)
+		case "after":
+			query.After, err = parseQueryTimestamp(value)
+		case "deleted":
+			var deleted bool
+			deleted, err = strconv.ParseBool(value)
+			query.Deleted = &deleted
+		default:
+			return nil, fmt.Errorf("unknown query term %q", name)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q: %s", name, value, err)
+		}
+	}
+}
+
+// parseQueryTimestamp parses s as nanoseconds since the Unix epoch or as an
+// RFC 3339 time.
+func parseQueryTimestamp(s string) (int64, error) {
+	if timestamp, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return timestamp, nil
+	}
+	t, err := time.Parse(time.RFC3339Nano, s)
+	if err != nil {
+		return 0, err
+	}
+	return Timestamp(t), nil
+}
+
+// Match returns true if the entry for key satisfies the query.
+func (query *Query) Match(key string, valueTimestamp *ValueTimestamp) bool {
+	if !strings.HasPrefix(key, query.Prefix) {
+		return false
+	}
+	if query.Before != 0 && valueTimestamp.Timestamp >= query.Before {
+		return false
+	}
+	if query.After != 0 && valueTimestamp.Timestamp <= query.After {
+		return false
+	}
+	if query.Deleted != nil && *query.Deleted != (valueTimestamp.Value == nil) {
+		return false
+	}
+	if query.Contains != "" || query.Regexp != nil {
+		if valueTimestamp.Value == nil {
+			return false
+		}
+		if !strings.Contains(*valueTimestamp.Value, query.Contains) {
+			return false
+		}
+		if query.Regexp != nil && !query.Regexp.MatchString(*valueTimestamp.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// Query returns a new Store holding copies of the entries, including deletion
+// markers, that match the query.
+func (store Store) Query(query *Query) Store {
+	store2 := Store{}
+	for key, valueTimestamp := range store {
+		if query.Match(key, valueTimestamp) {
+			valueTimestampCopy := *valueTimestamp
+			store2[key] = &valueTimestampCopy
+		}
+	}
+	return store2
+}
+
+// Query returns a new Store of the entries that match the query; see
+// Store.Query.
+func (syncStore *SyncStore) Query(query *Query) Store {
+	var store2 Store
+	syncStore.read(func(store Store) { store2 = store.Query(query) })
+	return store2
+}
@@ -0,0 +1,25 @@
+package kvt_test
+
+import (
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestParseQueryJunk(t *testing.T) {
+	for _, s := range []string{
+		"prefix",
+		`contains:"unterminated`,
+		"regex:[",
+		"before:yesterday",
+		"deleted:maybe",
+	} {
+		if _, err := kvt.ParseQuery(s); err == nil {
+			t.Error("expected error from", s)
+		}
+	}
+	query, err := kvt.ParseQuery("  ")
+	if err != nil || *query != (kvt.Query{}) {
+		t.Fatal(query, err)
+	}
+}
@@ -0,0 +1,41 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_Query() {
+	store := kvt.Store{}
+	store.SetTimestamped("config/a", "v1", 1)
+	store.SetTimestamped("config/b", "v22", 2)
+	store.SetTimestamped("config/c", "other value", 3)
+	store.DeleteTimestamped("config/d", 4)
+	store.SetTimestamped("data/a", "v3", 5)
+
+	for _, s := range []string{
+		`prefix:config/ regex:^v[0-9]+$`,
+		`contains:"r v"`,
+		`after:2 deleted:false`,
+		`prefix:config/ deleted:true`,
+		`before:1970-01-01T00:00:00.000000003Z`,
+	} {
+		query, err := kvt.ParseQuery(s)
+		if err != nil {
+			panic(err)
+		}
+		fmt.Println(store.Query(query))
+	}
+
+	_, err := kvt.ParseQuery("size:10")
+	fmt.Println(err)
+
+	// Output:
+	// {"config/a":["v1",1],"config/b":["v22",2]}
+	// {"config/c":["other value",3]}
+	// {"config/c":["other value",3],"data/a":["v3",5]}
+	// {"config/d":[null,4]}
+	// {"config/a":["v1",1],"config/b":["v22",2]}
+	// unknown query term "size"
+}
@@ -0,0 +1,65 @@
+package kvt
+
+// EnableRevisions has syncStore start counting revisions for each key: every
+// change to a key's entry, whether from a local write or an Absorb, increments
+// the key's revision by one. Unlike timestamps, revisions never go backwards
+// or collide, so they support conditional writes such as SetIfRevision.
+// Revisions are kept only in memory and start at 0 for every key when
+// enabled; calling EnableRevisions again has no effect.
+func (syncStore *SyncStore) EnableRevisions() {
+	syncStore.lock.Lock()
+	if syncStore.revisions == nil {
+		syncStore.revisions = map[string]uint64{}
+	}
+	syncStore.lock.Unlock()
+}
+
+// Revision returns the key's revision, which is 0 if it hasn't changed since
+// EnableRevisions was called, or if revisions aren't enabled.
+func (syncStore *SyncStore) Revision(key string) uint64 {
+	syncStore.lock.RLock()
+	revision := syncStore.revisions[key]
+	syncStore.lock.RUnlock()
+	return revision
+}
+
+// SetIfRevision does a Set only if the key's revision is still the revision
+// given, returning the key's revision afterwards and whether the entry was
+// changed. As with Set, the entry doesn't change, and so neither does its
+// revision, if the existing entry has a newer timestamp; the result is then
+// false even though the revision matched.
+func (syncStore *SyncStore) SetIfRevision(key string, value string, revision uint64) (uint64, bool) {
+	return syncStore.ifRevision(key, revision, func(store Store) { store.Set(key, value) })
+}
+
+// DeleteIfRevision does a Delete only if the key's revision is still the
+// revision given; see SetIfRevision.
+func (syncStore *SyncStore) DeleteIfRevision(key string, revision uint64) (uint64, bool) {
+	return syncStore.ifRevision(key, revision, func(store Store) { store.Delete(key) })
+}
+
+// ifRevision calls fn, which may only change the entry for key, if the key's
+// revision is wanted, returning the key's resulting revision and whether fn
+// changed the entry.
+func (syncStore *SyncStore) ifRevision(key string, wanted uint64, fn func(store Store)) (uint64, bool) {
+	var revision uint64
+	var ok bool
+	syncStore.writeKeys([]string{key}, func(store Store) {
+		revision = syncStore.revisions[key]
+		if revision != wanted {
+			return
+		}
+		var before *ValueTimestamp
+		if valueTimestamp := store[key]; valueTimestamp != nil {
+			valueTimestampCopy := *valueTimestamp
+			before = &valueTimestampCopy
+		}
+		fn(store)
+		if before == nil || !before.equal(store[key]) {
+			// change will bump the revision once fn returns.
+			revision++
+			ok = true
+		}
+	})
+	return revision, ok
+}
@@ -0,0 +1,39 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore_SetIfRevision() {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.EnableRevisions()
+	syncStore.Set("A", "one")
+	revision := syncStore.Revision("A")
+	fmt.Println(revision)
+
+	// Two clients read revision 1 and both try to update A; only the first
+	// succeeds.
+	fmt.Println(syncStore.SetIfRevision("A", "two", revision))
+	fmt.Println(syncStore.SetIfRevision("A", "three", revision))
+	fmt.Println(syncStore.Get("A"))
+
+	// Absorbed changes bump revisions too.
+	store2 := kvt.Store{}
+	store2.SetTimestamped("A", "four", kvt.MaxTimestamp)
+	syncStore.Absorb(store2)
+	fmt.Println(syncStore.Revision("A"))
+
+	// The revision matches, but the entry's newer timestamp still wins, so the
+	// delete changes nothing and reports false.
+	fmt.Println(syncStore.DeleteIfRevision("A", 3))
+
+	// Output:
+	// 1
+	// 2 true
+	// 2 false
+	// two
+	// 3
+	// 3 false
+}
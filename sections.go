@@ -0,0 +1,143 @@
+package kvt
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"sort"
+)
+
+// sectionsHeader is the first line of a sectioned snapshot.
+const sectionsHeader = "kvt-sections 1\n"
+
+// Section describes one named store within a sectioned snapshot, as written
+// by WriteSections.
+type Section struct {
+	Name string
+	// Hash is the Store.Hash of the section's store when written.
+	Hash string
+	// Offset is where the section's JSON encoded store begins, relative to
+	// the end of the index.
+	Offset int64
+	// Length is the length in bytes of the section's JSON encoded store.
+	Length int64
+}
+
+// WriteSections writes the named stores to w as one sectioned snapshot. The
+// snapshot starts with an index of the sections, so a single section can later
+// be read with ReadSection without decoding the others.
+func WriteSections(w io.Writer, stores map[string]Store) error {
+	names := make([]string, 0, len(stores))
+	for name := range stores {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	sections := make([]*Section, 0, len(names))
+	var body bytes.Buffer
+	for _, name := range names {
+		b, err := json.Marshal(stores[name])
+		if err != nil {
+			return err
+		}
+		sections = append(sections, &Section{Name: name, Hash: stores[name].Hash(), Offset: int64(body.Len()), Length: int64(len(b))})
+		body.Write(b)
+	}
+	index, err := json.Marshal(sections)
+	if err != nil {
+		return err
+	}
+	if _, err = io.WriteString(w, sectionsHeader); err != nil {
+		return err
+	}
+	if _, err = w.Write(append(index, '\n')); err != nil {
+		return err
+	}
+	_, err = body.WriteTo(w)
+	return err
+}
+
+// ReadSections returns the index of the sectioned snapshot in r.
+func ReadSections(r io.ReaderAt) ([]*Section, error) {
+	sections, _, err := readSectionIndex(r)
+	return sections, err
+}
+
+// ReadSection reads just the named section's store from the sectioned
+// snapshot in r. An error is returned if there is no such section or if the
+// store read does not match the hash recorded for it.
+func ReadSection(r io.ReaderAt, name string) (Store, error) {
+	sections, base, err := readSectionIndex(r)
+	if err != nil {
+		return nil, err
+	}
+	for _, section := range sections {
+		if section.Name != name {
+			continue
+		}
+		if section.Offset < 0 || section.Length < 0 || section.Offset > math.MaxInt64-base-section.Length {
+			return nil, fmt.Errorf("section %q has invalid offset %d and length %d", name, section.Offset, section.Length)
+		}
+		// Read with ReadAll, rather than into a buffer of section.Length,
+		// so a damaged index can't make us allocate more than r holds.
+		b, err := io.ReadAll(io.NewSectionReader(r, base+section.Offset, section.Length))
+		if err != nil {
+			return nil, fmt.Errorf("reading section %q: %s", name, err)
+		}
+		if int64(len(b)) != section.Length {
+			return nil, fmt.Errorf("reading section %q: extends past the end of the snapshot", name)
+		}
+		store := Store{}
+		if err = json.Unmarshal(b, &store); err != nil {
+			return nil, fmt.Errorf("decoding section %q: %s", name, err)
+		}
+		if hash := store.Hash(); hash != section.Hash {
+			return nil, fmt.Errorf("section %q has hash %s; expected %s", name, hash, section.Hash)
+		}
+		return store, nil
+	}
+	return nil, fmt.Errorf("no section %q", name)
+}
+
+// readSectionIndex returns the index of the sectioned snapshot in r and the
+// offset where the section data begins.
+func readSectionIndex(r io.ReaderAt) ([]*Section, int64, error) {
+	reader := bufio.NewReader(io.NewSectionReader(r, 0, 1<<62))
+	header, err := reader.ReadString('\n')
+	if err != nil || header != sectionsHeader {
+		return nil, 0, fmt.Errorf("not a sectioned snapshot")
+	}
+	index, err := reader.ReadBytes('\n')
+	if err != nil {
+		return nil, 0, fmt.Errorf("reading section index: %s", err)
+	}
+	var sections []*Section
+	if err = json.Unmarshal(index, &sections); err != nil {
+		return nil, 0, fmt.Errorf("decoding section index: %s", err)
+	}
+	return sections, int64(len(header) + len(index)), nil
+}
+
+// SaveSectionsFile atomically writes the named stores to the file at path as
+// one sectioned snapshot; see WriteSections.
+func SaveSectionsFile(path string, stores map[string]Store) error {
+	var buf bytes.Buffer
+	if err := WriteSections(&buf, stores); err != nil {
+		return err
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// LoadSectionFile reads just the named section's store from the sectioned
+// snapshot file at path; see ReadSection.
+func LoadSectionFile(path string, name string) (Store, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ReadSection(f, name)
+}
@@ -0,0 +1,23 @@
+package kvt_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestReadSectionBadIndex(t *testing.T) {
+	for _, index := range []string{
+		`[{"Name":"A","Offset":-1,"Length":2}]`,
+		`[{"Name":"A","Offset":0,"Length":-1}]`,
+		`[{"Name":"A","Offset":0,"Length":9223372036854775807}]`,
+		`[{"Name":"A","Offset":9223372036854775807,"Length":2}]`,
+		`[{"Name":"A","Offset":1,"Length":2}]`,
+	} {
+		snapshot := "kvt-sections 1\n" + index + "\n{}"
+		if _, err := kvt.ReadSection(strings.NewReader(snapshot), "A"); err == nil {
+			t.Errorf("expected error from %s", index)
+		}
+	}
+}
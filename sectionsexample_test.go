@@ -0,0 +1,70 @@
+package kvt_test
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleWriteSections() {
+	hosts := kvt.Store{}
+	hosts.SetTimestamped("alpha", "10.0.0.1", 1)
+	services := kvt.Store{}
+	services.SetTimestamped("web", "alpha:80", 2)
+	var buf bytes.Buffer
+	if err := kvt.WriteSections(&buf, map[string]kvt.Store{"hosts": hosts, "services": services}); err != nil {
+		panic(err)
+	}
+	fmt.Print(buf.String())
+
+	// Output:
+	// kvt-sections 1
+	// [{"Name":"hosts","Hash":"7284ab9c3d378da4","Offset":0,"Length":24},{"Name":"services","Hash":"78ff183a80050f79","Offset":24,"Length":22}]
+	// {"alpha":["10.0.0.1",1]}{"web":["alpha:80",2]}
+}
+
+func ExampleReadSection() {
+	hosts := kvt.Store{}
+	hosts.SetTimestamped("alpha", "10.0.0.1", 1)
+	services := kvt.Store{}
+	services.SetTimestamped("web", "alpha:80", 2)
+	var buf bytes.Buffer
+	if err := kvt.WriteSections(&buf, map[string]kvt.Store{"hosts": hosts, "services": services}); err != nil {
+		panic(err)
+	}
+	r := bytes.NewReader(buf.Bytes())
+	sections, err := kvt.ReadSections(r)
+	for _, section := range sections {
+		fmt.Println(section.Name, section.Hash, err)
+	}
+	fmt.Println(kvt.ReadSection(r, "services"))
+	fmt.Println(kvt.ReadSection(r, "nothing"))
+
+	// Output:
+	// hosts 7284ab9c3d378da4 <nil>
+	// services 78ff183a80050f79 <nil>
+	// {"web":["alpha:80",2]} <nil>
+	// null no section "nothing"
+}
+
+func ExampleLoadSectionFile() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "sections")
+	hosts := kvt.Store{}
+	hosts.SetTimestamped("alpha", "10.0.0.1", 1)
+	services := kvt.Store{}
+	services.SetTimestamped("web", "alpha:80", 2)
+	fmt.Println(kvt.SaveSectionsFile(path, map[string]kvt.Store{"hosts": hosts, "services": services}))
+	fmt.Println(kvt.LoadSectionFile(path, "hosts"))
+
+	// Output:
+	// <nil>
+	// {"alpha":["10.0.0.1",1]} <nil>
+}
@@ -0,0 +1,174 @@
+package kvt
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// segmentsManifest is the name of the file listing a segmented snapshot's
+// segments.
+const segmentsManifest = "manifest.json"
+
+// Segment describes one key range of a segmented snapshot, as written by
+// SaveSegments.
+type Segment struct {
+	// Start is the first key the segment may hold; the segment covers keys
+	// up to, but not including, the next segment's Start.
+	Start string
+	// File is the name of the segment's file within the snapshot directory.
+	// It is derived from the file's contents, so an unchanged segment keeps
+	// its file.
+	File string
+}
+
+// SaveSegments saves the store to the directory at dir as a segmented
+// snapshot: the keys are split into ranges of at most maxEntries entries, each
+// saved to its own file, plus a manifest of the ranges. When the directory
+// already holds a segmented snapshot, its ranges are reused and only the
+// segment files whose contents have changed are written, so saving a large
+// store after a small change is cheap. Ranges that have grown beyond
+// maxEntries are split. It returns how many segment files were written.
+//
+// The manifest is replaced atomically after the new segment files are in
+// place, and only then are unused segment files removed, so a crash part way
+// through leaves the previous snapshot intact.
+func (store Store) SaveSegments(dir string, maxEntries int) (int, error) {
+	if maxEntries < 1 {
+		maxEntries = 1
+	}
+	if err := os.MkdirAll(dir, 0777); err != nil {
+		return 0, err
+	}
+	previous, err := ReadSegments(dir)
+	if err != nil && !os.IsNotExist(err) {
+		return 0, err
+	}
+	starts := []string{""}
+	for _, segment := range previous {
+		if segment.Start != "" {
+			starts = append(starts, segment.Start)
+		}
+	}
+	keys := store.sortedKeys()
+	var segments []*Segment
+	written := 0
+	for i, start := range starts {
+		var rangeKeys []string
+		for len(keys) > 0 && (i == len(starts)-1 || keys[0] < starts[i+1]) {
+			rangeKeys = append(rangeKeys, keys[0])
+			keys = keys[1:]
+		}
+		if len(rangeKeys) == 0 && start != "" {
+			// Empty ranges are folded into the range before them.
+			continue
+		}
+		for first := true; first || len(rangeKeys) > 0; first = false {
+			n := len(rangeKeys)
+			if n > maxEntries {
+				n = maxEntries
+			}
+			segmentStart := start
+			if !first {
+				segmentStart = rangeKeys[0]
+			}
+			segment, wrote, err := writeSegment(dir, segmentStart, store, rangeKeys[:n])
+			if err != nil {
+				return written, err
+			}
+			if wrote {
+				written++
+			}
+			segments = append(segments, segment)
+			rangeKeys = rangeKeys[n:]
+		}
+	}
+	b, err := json.Marshal(segments)
+	if err != nil {
+		return written, err
+	}
+	if err = writeFileAtomic(filepath.Join(dir, segmentsManifest), b); err != nil {
+		return written, err
+	}
+	used := map[string]bool{}
+	for _, segment := range segments {
+		used[segment.File] = true
+	}
+	for _, segment := range previous {
+		if !used[segment.File] {
+			if err = os.Remove(filepath.Join(dir, segment.File)); err != nil && !os.IsNotExist(err) {
+				return written, err
+			}
+		}
+	}
+	return written, nil
+}
+
+// writeSegment writes the entries for keys to a segment file named for its
+// contents, unless that file already exists, returning whether it wrote it.
+func writeSegment(dir string, start string, store Store, keys []string) (*Segment, bool, error) {
+	part := make(Store, len(keys))
+	for _, key := range keys {
+		part[key] = store[key]
+	}
+	b, err := json.Marshal(part)
+	if err != nil {
+		return nil, false, err
+	}
+	hasher := fnv.New64a()
+	hasher.Write([]byte(start))
+	hasher.Write([]byte{0})
+	hasher.Write(b)
+	segment := &Segment{Start: start, File: fmt.Sprintf("segment-%016x.json", hasher.Sum64())}
+	path := filepath.Join(dir, segment.File)
+	if _, err = os.Stat(path); err == nil {
+		return segment, false, nil
+	}
+	if err = writeFileAtomic(path, b); err != nil {
+		return nil, false, err
+	}
+	return segment, true, nil
+}
+
+// ReadSegments returns the segments listed in the manifest of the segmented
+// snapshot in dir.
+func ReadSegments(dir string) ([]*Segment, error) {
+	b, err := os.ReadFile(filepath.Join(dir, segmentsManifest))
+	if err != nil {
+		return nil, err
+	}
+	var segments []*Segment
+	if err = json.Unmarshal(b, &segments); err != nil {
+		return nil, fmt.Errorf("%s: %s", filepath.Join(dir, segmentsManifest), err)
+	}
+	if !sort.SliceIsSorted(segments, func(i, j int) bool { return segments[i].Start < segments[j].Start }) {
+		return nil, fmt.Errorf("%s: segments out of order", filepath.Join(dir, segmentsManifest))
+	}
+	for _, segment := range segments {
+		if strings.ContainsAny(segment.File, `/\`) {
+			return nil, fmt.Errorf("%s: invalid segment file %q", filepath.Join(dir, segmentsManifest), segment.File)
+		}
+	}
+	return segments, nil
+}
+
+// LoadSegments loads the segmented snapshot in dir, as saved by SaveSegments.
+func LoadSegments(dir string) (Store, error) {
+	segments, err := ReadSegments(dir)
+	if err != nil {
+		return nil, err
+	}
+	store := Store{}
+	for _, segment := range segments {
+		part, err := LoadFile(filepath.Join(dir, segment.File))
+		if err != nil {
+			return nil, err
+		}
+		store.Absorb(part)
+	}
+	return store, nil
+}
@@ -0,0 +1,66 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestSaveSegments(t *testing.T) {
+	dir := t.TempDir()
+	store := kvt.Store{}
+	for i := 0; i < 10; i++ {
+		store.SetTimestamped(fmt.Sprintf("k%02d", i), "v", 1)
+	}
+	check := func(expectedWritten int, expectedSegments int) {
+		t.Helper()
+		written, err := store.SaveSegments(dir, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segments, err := kvt.ReadSegments(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if written != expectedWritten || len(segments) != expectedSegments {
+			t.Fatalf("wrote %d of %d segments, expected %d of %d", written, len(segments), expectedWritten, expectedSegments)
+		}
+		loaded, err := kvt.LoadSegments(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a, b := loaded.String(), store.String(); a != b {
+			t.Fatal(a, b)
+		}
+		files, _ := filepath.Glob(filepath.Join(dir, "segment-*"))
+		if len(files) != len(segments) {
+			t.Fatal("unused segment files left behind:", files)
+		}
+	}
+	check(3, 3)
+	// Nothing changed, so nothing is written.
+	check(0, 3)
+	// A change to one key rewrites just its segment.
+	store.SetTimestamped("k05", "changed", 2)
+	check(1, 3)
+	// A range that grows too large is split.
+	for i := 0; i < 5; i++ {
+		store.SetTimestamped(fmt.Sprintf("k05-%d", i), "v", 1)
+	}
+	check(3, 5)
+	// A range left empty is dropped, its keys now falling in the range
+	// before it.
+	store = store.Filter(func(key string, valueTimestamp *kvt.ValueTimestamp) bool {
+		return key < "k04" || key >= "k05-3"
+	})
+	check(1, 4)
+}
+
+func TestLoadSegmentsMissing(t *testing.T) {
+	if _, err := kvt.LoadSegments(t.TempDir()); !os.IsNotExist(err) {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,174 @@
+package kvt
+
+import (
+	"encoding/json"
+	"hash/fnv"
+	"sync"
+)
+
+// ShardedStore spreads its entries across several SyncStores by key hash, so
+// that writers to different keys seldom wait on one another. Methods covering
+// the whole store, such as Hash and Copy, lock one shard at a time and so may
+// not see a single point in time if there are concurrent writers.
+//
+// The zero value is an empty store with a single shard; use NewShardedStore
+// for more.
+type ShardedStore struct {
+	// init sets shards for a zero value ShardedStore.
+	init   sync.Once
+	shards []*SyncStore
+}
+
+// NewShardedStore returns an empty ShardedStore with the number of shards
+// given; at least one shard is always used.
+func NewShardedStore(shards int) *ShardedStore {
+	if shards < 1 {
+		shards = 1
+	}
+	shardedStore := &ShardedStore{shards: make([]*SyncStore, shards)}
+	for i := range shardedStore.shards {
+		shardedStore.shards[i] = NewSyncStore(nil)
+	}
+	return shardedStore
+}
+
+// allShards returns the shards, first giving a zero value ShardedStore its
+// single shard.
+func (shardedStore *ShardedStore) allShards() []*SyncStore {
+	shardedStore.init.Do(func() {
+		if shardedStore.shards == nil {
+			shardedStore.shards = []*SyncStore{NewSyncStore(nil)}
+		}
+	})
+	return shardedStore.shards
+}
+
+// shard returns the shard responsible for the key.
+func (shardedStore *ShardedStore) shard(key string) *SyncStore {
+	shards := shardedStore.allShards()
+	return shards[shardIndex(key, len(shards))]
+}
+
+// shardIndex returns which of count shards is responsible for the key.
+func shardIndex(key string, count int) int {
+	hasher := fnv.New32a()
+	hasher.Write([]byte(key))
+	return int(hasher.Sum32() % uint32(count))
+}
+
+// Get returns the value for a key; see Store.Get.
+func (shardedStore *ShardedStore) Get(key string) string {
+	return shardedStore.shard(key).Get(key)
+}
+
+// Set is equivalent to SetTimestamped(key, value, Now()).
+func (shardedStore *ShardedStore) Set(key string, value string) {
+	shardedStore.shard(key).Set(key, value)
+}
+
+// SetTimestamped stores the value for the key unless there is a newer entry;
+// see Store.SetTimestamped.
+func (shardedStore *ShardedStore) SetTimestamped(key string, value string, timestamp int64) {
+	shardedStore.shard(key).SetTimestamped(key, value, timestamp)
+}
+
+// Delete is equivalent to DeleteTimestamped(key, Now()).
+func (shardedStore *ShardedStore) Delete(key string) {
+	shardedStore.shard(key).Delete(key)
+}
+
+// DeleteTimestamped records a deletion marker for the key unless there is a
+// newer entry; see Store.DeleteTimestamped.
+func (shardedStore *ShardedStore) DeleteTimestamped(key string, timestamp int64) {
+	shardedStore.shard(key).DeleteTimestamped(key, timestamp)
+}
+
+// Purge discards deletion markers older than the cutoff; see Store.Purge.
+func (shardedStore *ShardedStore) Purge(cutoff int64) {
+	for _, shard := range shardedStore.allShards() {
+		shard.Purge(cutoff)
+	}
+}
+
+// Absorb updates shardedStore with any newer entries from store2; see
+// Store.Absorb.
+func (shardedStore *ShardedStore) Absorb(store2 Store) {
+	shards := shardedStore.allShards()
+	parts := make([]Store, len(shards))
+	for key, valueTimestamp2 := range store2 {
+		i := shardIndex(key, len(parts))
+		if parts[i] == nil {
+			parts[i] = Store{}
+		}
+		parts[i][key] = valueTimestamp2
+	}
+	for i, part := range parts {
+		if part != nil {
+			shards[i].Absorb(part)
+		}
+	}
+}
+
+// AbsorbAll updates shardedStore with the newest entries from all of stores;
+// see Store.AbsorbAll. The entries are first split up by shard and then each
+// shard absorbs its part in its own goroutine.
+func (shardedStore *ShardedStore) AbsorbAll(stores ...Store) {
+	shards := shardedStore.allShards()
+	parts := make([]Store, len(shards))
+	for _, store2 := range stores {
+		for key, valueTimestamp2 := range store2 {
+			i := shardIndex(key, len(parts))
+			if parts[i] == nil {
+				parts[i] = Store{}
+			}
+			parts[i].absorbEntry(key, valueTimestamp2)
+		}
+	}
+	var wg sync.WaitGroup
+	for i, part := range parts {
+		if part != nil {
+			wg.Add(1)
+			go func(shard *SyncStore, part Store) {
+				shard.Absorb(part)
+				wg.Done()
+			}(shards[i], part)
+		}
+	}
+	wg.Wait()
+}
+
+// ModifiedSince returns a new Store of the entries newer than the timestamp;
+// see Store.ModifiedSince.
+func (shardedStore *ShardedStore) ModifiedSince(timestamp int64) Store {
+	store2 := Store{}
+	for _, shard := range shardedStore.allShards() {
+		store2.Absorb(shard.ModifiedSince(timestamp))
+	}
+	return store2
+}
+
+// Copy returns a new Store holding copies of all the entries; see Store.Copy.
+func (shardedStore *ShardedStore) Copy() Store {
+	store2 := Store{}
+	for _, shard := range shardedStore.allShards() {
+		store2.Absorb(shard.Copy())
+	}
+	return store2
+}
+
+// Hash returns a hash of the keys, timestamps, and flags, identical to what
+// Store.Hash would return for the same entries.
+func (shardedStore *ShardedStore) Hash() string {
+	return shardedStore.Copy().Hash()
+}
+
+// MarshalJSON returns the JSON encoded version of the store or an error.
+func (shardedStore *ShardedStore) MarshalJSON() ([]byte, error) {
+	return json.Marshal(shardedStore.Copy())
+}
+
+// String returns the JSON encoded string representation of the store; see
+// Store.String.
+func (shardedStore *ShardedStore) String() string {
+	return shardedStore.Copy().String()
+}
@@ -0,0 +1,54 @@
+package kvt_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestShardedStoreAbsorbPurge(t *testing.T) {
+	shardedStore := kvt.NewShardedStore(0)
+	store2 := kvt.Store{}
+	store2.SetTimestamped("A", "one", 1)
+	store2.DeleteTimestamped("B", 1)
+	store2.DeleteTimestamped("C", 5)
+	shardedStore.Absorb(store2)
+	shardedStore.Purge(3)
+	if s := shardedStore.String(); s != `{"A":["one",1],"C":[null,5]}` {
+		t.Fatal(s)
+	}
+	if s := shardedStore.ModifiedSince(1).String(); s != `{"C":[null,5]}` {
+		t.Fatal(s)
+	}
+}
+
+func TestShardedStoreAbsorbAll(t *testing.T) {
+	shardedStore := kvt.NewShardedStore(4)
+	var stores []kvt.Store
+	expected := kvt.Store{}
+	for i := 0; i < 8; i++ {
+		store2 := kvt.Store{}
+		for j := 0; j < 50; j++ {
+			store2.SetTimestamped(fmt.Sprintf("k%d", j), fmt.Sprintf("v%d", i), int64(1+(i*j)%7))
+		}
+		stores = append(stores, store2)
+		expected.Absorb(store2)
+	}
+	shardedStore.AbsorbAll(stores...)
+	if a, b := shardedStore.String(), expected.String(); a != b {
+		t.Fatal(a, b)
+	}
+}
+
+func TestShardedStoreZeroValue(t *testing.T) {
+	var shardedStore kvt.ShardedStore
+	if s := shardedStore.Get("A"); s != "" {
+		t.Fatal(s)
+	}
+	shardedStore.SetTimestamped("A", "one", 1)
+	shardedStore.Absorb(kvt.Store{"B": {Timestamp: 2}})
+	if s := shardedStore.String(); s != `{"A":["one",1],"B":[null,2]}` {
+		t.Fatal(s)
+	}
+}
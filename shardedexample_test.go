@@ -0,0 +1,33 @@
+package kvt_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleShardedStore() {
+	shardedStore := kvt.NewShardedStore(16)
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			for j := 0; j < 3; j++ {
+				shardedStore.SetTimestamped(fmt.Sprintf("w%d/k%d", i, j), "v", 1)
+			}
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	shardedStore.DeleteTimestamped("w0/k0", 2)
+
+	// The hash matches that of a plain Store with the same entries.
+	store := shardedStore.Copy()
+	fmt.Println(len(store), shardedStore.Hash() == store.Hash())
+	fmt.Println(shardedStore.Get("w1/k2"), shardedStore.Get("w0/k0") == "")
+
+	// Output:
+	// 12 true
+	// v true
+}
@@ -0,0 +1,46 @@
+package kvt
+
+import (
+	"database/sql/driver"
+	"fmt"
+)
+
+// Value implements driver.Valuer, so a Store can be written to a single JSON
+// or JSONB column through database/sql. The value is the JSON given by
+// MarshalJSON, as a string, or NULL for a nil Store.
+func (store Store) Value() (driver.Value, error) {
+	if store == nil {
+		return nil, nil
+	}
+	b, err := store.MarshalJSON()
+	if err != nil {
+		return nil, err
+	}
+	return string(b), nil
+}
+
+// Scan implements sql.Scanner, replacing the store with the one read from a
+// column written by Value; NULL gives a nil Store.
+func (store *Store) Scan(src interface{}) error {
+	var b []byte
+	switch src := src.(type) {
+	case nil:
+		*store = nil
+		return nil
+	case []byte:
+		b = src
+	case string:
+		b = []byte(src)
+	default:
+		return fmt.Errorf("kvt: cannot scan %T into a Store", src)
+	}
+	var store2 Store
+	if err := store2.UnmarshalJSON(b); err != nil {
+		return err
+	}
+	if store2 == nil {
+		store2 = Store{}
+	}
+	*store = store2
+	return nil
+}
@@ -0,0 +1,46 @@
+package kvt_test
+
+import (
+	"database/sql"
+	"database/sql/driver"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+var (
+	_ driver.Valuer = kvt.Store{}
+	_ sql.Scanner   = &kvt.Store{}
+)
+
+func TestStoreValueScan(t *testing.T) {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	store.DeleteTimestamped("B", 2)
+	value, err := store.Value()
+	if err != nil || value != `{"A":["one",1],"B":[null,2]}` {
+		t.Fatal(value, err)
+	}
+	for _, src := range []interface{}{value, []byte(value.(string))} {
+		scanned := kvt.Store{"stale": {}}
+		if err = scanned.Scan(src); err != nil || scanned.String() != store.String() {
+			t.Fatal(scanned, err)
+		}
+	}
+
+	if value, err = kvt.Store(nil).Value(); value != nil || err != nil {
+		t.Fatal(value, err)
+	}
+	scanned := kvt.Store{}
+	if err = scanned.Scan(nil); scanned != nil || err != nil {
+		t.Fatal(scanned, err)
+	}
+	if err = scanned.Scan(`null`); scanned == nil || len(scanned) != 0 || err != nil {
+		t.Fatal(scanned, err)
+	}
+	for _, junk := range []interface{}{1, `{"A":null}`, `[]`} {
+		if err = scanned.Scan(junk); err == nil {
+			t.Errorf("expected error from %v", junk)
+		}
+	}
+}
@@ -0,0 +1,26 @@
+package kvt
+
+// Storer is the set of methods shared by Store and the implementations built
+// for concurrent use, such as SyncStore, ShardedStore, AtomicStore, and
+// MapStore; all of them follow Store's semantics.
+type Storer interface {
+	Get(key string) string
+	Set(key string, value string)
+	SetTimestamped(key string, value string, timestamp int64)
+	Delete(key string)
+	DeleteTimestamped(key string, timestamp int64)
+	Purge(cutoff int64)
+	Absorb(store2 Store)
+	ModifiedSince(timestamp int64) Store
+	Copy() Store
+	Hash() string
+}
+
+var (
+	_ Storer = Store{}
+	_ Storer = &SyncStore{}
+	_ Storer = &ShardedStore{}
+	_ Storer = &AtomicStore{}
+	_ Storer = &FrozenStore{}
+	_ Storer = &MapStore{}
+)
@@ -0,0 +1,9 @@
+//go:build !unix
+
+package kvt
+
+// syncDir is a no-op on platforms where directories can't be synced; there,
+// a crash just after a rename may still lose it.
+func syncDir(path string) error {
+	return nil
+}
@@ -0,0 +1,19 @@
+//go:build unix
+
+package kvt
+
+import "os"
+
+// syncDir syncs the directory at path, so that renames and file creations in
+// it are durable.
+func syncDir(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	err = f.Sync()
+	if err2 := f.Close(); err == nil {
+		err = err2
+	}
+	return err
+}
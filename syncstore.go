@@ -0,0 +1,578 @@
+package kvt
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// SyncStore is a Store guarded by a sync.RWMutex so that it can be used from
+// multiple goroutines at once. The zero value is an empty SyncStore ready for
+// use. Its methods are the same as Store's; see Store for their details.
+type SyncStore struct {
+	lock  sync.RWMutex
+	store Store
+	// shared is set when store has been handed out by Snapshot and so must be
+	// copied before it is next written to.
+	shared   bool
+	watchers map[*watcher]bool
+	hooks    hooks
+	// revisions is nil unless EnableRevisions has been called.
+	revisions map[string]uint64
+	// purgeInfo is saved alongside the store by SaveFile.
+	purgeInfo PurgeInfo
+}
+
+// NewSyncStore returns a SyncStore wrapping store, which should no longer be
+// used directly afterwards. If store is nil, a new empty Store is used.
+func NewSyncStore(store Store) *SyncStore {
+	if store == nil {
+		store = Store{}
+	}
+	return &SyncStore{store: store}
+}
+
+// read calls fn with the store while holding the read lock.
+func (syncStore *SyncStore) read(fn func(store Store)) {
+	syncStore.lock.RLock()
+	defer syncStore.lock.RUnlock()
+	fn(syncStore.store)
+}
+
+// write calls fn with the store while holding the write lock; fn may change
+// any of the entries.
+func (syncStore *SyncStore) write(fn func(store Store)) {
+	syncStore.change(nil, false, fn)
+}
+
+// writeKeys is like write but fn may only change the entries for keys.
+func (syncStore *SyncStore) writeKeys(keys []string, fn func(store Store)) {
+	syncStore.change(func() []string { return keys }, false, fn)
+}
+
+// absorb is like write but fn may only change the entries for the keys in
+// store2, as renamed by remap, and the changes are reported as absorbed.
+func (syncStore *SyncStore) absorb(store2 Store, remap map[string]string, fn func(store Store)) {
+	syncStore.change(func() []string { return store2.keys(remap) }, true, fn)
+}
+
+// change calls fn with the store while holding the write lock. If anything is
+// watching for changes, or revisions are enabled, the entries for the keys
+// returned by keys, or all entries if keys is nil, are compared before and
+// after fn to find what changed; the changes bump the keys' revisions and are
+// reported in key order. Hooks are called after the lock is released.
+func (syncStore *SyncStore) change(keys func() []string, absorbed bool, fn func(store Store)) {
+	changes, hooks := syncStore.changeLocked(keys, absorbed, fn)
+	hooks.call(changes)
+}
+
+// changeLocked does the work of change while holding the write lock,
+// returning the changes and the hooks to call with them.
+func (syncStore *SyncStore) changeLocked(keys func() []string, absorbed bool, fn func(store Store)) ([]Change, hooks) {
+	syncStore.lock.Lock()
+	defer syncStore.lock.Unlock()
+	if syncStore.store == nil {
+		syncStore.store = Store{}
+	} else if syncStore.shared {
+		syncStore.store = syncStore.store.Copy()
+		syncStore.shared = false
+	}
+	if len(syncStore.watchers) == 0 && syncStore.hooks.empty() && syncStore.revisions == nil {
+		fn(syncStore.store)
+		return nil, hooks{}
+	}
+	var before Store
+	var ks []string
+	if keys == nil {
+		before = syncStore.store.Copy()
+	} else {
+		ks = keys()
+		before = make(Store, len(ks))
+		for _, key := range ks {
+			if valueTimestamp := syncStore.store[key]; valueTimestamp != nil {
+				valueTimestampCopy := *valueTimestamp
+				before[key] = &valueTimestampCopy
+			} else {
+				before[key] = nil
+			}
+		}
+	}
+	fn(syncStore.store)
+	var changes []Change
+	check := func(key string) {
+		old, current := before[key], syncStore.store[key]
+		if current == nil || (old != nil && old.equal(current)) {
+			return
+		}
+		valueTimestampCopy := *current
+		changes = append(changes, Change{Key: key, Old: old, New: &valueTimestampCopy, Absorbed: absorbed})
+	}
+	if keys == nil {
+		for _, key := range syncStore.store.sortedKeys() {
+			check(key)
+		}
+	} else {
+		sort.Strings(ks)
+		for i, key := range ks {
+			if i == 0 || key != ks[i-1] {
+				check(key)
+			}
+		}
+	}
+	for _, change := range changes {
+		if syncStore.revisions != nil {
+			syncStore.revisions[change.Key]++
+		}
+		for watcher := range syncStore.watchers {
+			if strings.HasPrefix(change.Key, watcher.prefix) {
+				watcher.push(change)
+			}
+		}
+	}
+	return changes, syncStore.hooks
+}
+
+// View calls fn with the underlying Store while holding the read lock, so
+// several reads, such as a Hash and a PrefixKeys, see the same state. fn must
+// not modify the Store, keep it after returning, or use syncStore.
+func (syncStore *SyncStore) View(fn func(store Store)) {
+	syncStore.lock.RLock()
+	defer syncStore.lock.RUnlock()
+	store := syncStore.store
+	if store == nil {
+		store = Store{}
+	}
+	fn(store)
+}
+
+// Snapshot returns the current contents as a Store that will not change, even
+// as syncStore continues to be written to. Taking the snapshot is O(1); the
+// cost of copying is instead paid by the next write to syncStore, which
+// copies the whole store, O(n), while holding the write lock, so that write
+// and any others waiting on the lock are held up for the copy. The Store
+// returned is shared and must not be modified.
+func (syncStore *SyncStore) Snapshot() Store {
+	syncStore.lock.Lock()
+	if syncStore.store == nil {
+		syncStore.store = Store{}
+	}
+	syncStore.shared = true
+	store := syncStore.store
+	syncStore.lock.Unlock()
+	return store
+}
+
+// Get returns the value for a key; see Store.Get.
+func (syncStore *SyncStore) Get(key string) (value string) {
+	syncStore.read(func(store Store) { value = store.Get(key) })
+	return value
+}
+
+// Set is equivalent to SetTimestamped(key, value, Now()).
+func (syncStore *SyncStore) Set(key string, value string) {
+	syncStore.writeKeys([]string{key}, func(store Store) { store.Set(key, value) })
+}
+
+// SetTimestamped stores the value for the key unless there is a newer entry;
+// see Store.SetTimestamped.
+func (syncStore *SyncStore) SetTimestamped(key string, value string, timestamp int64) {
+	syncStore.writeKeys([]string{key}, func(store Store) { store.SetTimestamped(key, value, timestamp) })
+}
+
+// Delete is equivalent to DeleteTimestamped(key, Now()).
+func (syncStore *SyncStore) Delete(key string) {
+	syncStore.writeKeys([]string{key}, func(store Store) { store.Delete(key) })
+}
+
+// DeleteTimestamped records a deletion marker for the key unless there is a
+// newer entry; see Store.DeleteTimestamped.
+func (syncStore *SyncStore) DeleteTimestamped(key string, timestamp int64) {
+	syncStore.writeKeys([]string{key}, func(store Store) { store.DeleteTimestamped(key, timestamp) })
+}
+
+// GetOrSet returns the existing value or sets the one given; see
+// Store.GetOrSet.
+func (syncStore *SyncStore) GetOrSet(key string, value string) (actual string, loaded bool) {
+	syncStore.writeKeys([]string{key}, func(store Store) { actual, loaded = store.GetOrSet(key, value) })
+	return actual, loaded
+}
+
+// Swap sets the value and returns the previous one; see Store.Swap.
+func (syncStore *SyncStore) Swap(key string, value string) (previous string, loaded bool, swapped bool) {
+	syncStore.writeKeys([]string{key}, func(store Store) { previous, loaded, swapped = store.Swap(key, value) })
+	return previous, loaded, swapped
+}
+
+// LoadAndDelete deletes the key and returns its previous value; see
+// Store.LoadAndDelete.
+func (syncStore *SyncStore) LoadAndDelete(key string) (value string, loaded bool, deleted bool) {
+	syncStore.writeKeys([]string{key}, func(store Store) { value, loaded, deleted = store.LoadAndDelete(key) })
+	return value, loaded, deleted
+}
+
+// Touch is equivalent to TouchTimestamped(key, Now()).
+func (syncStore *SyncStore) Touch(key string) (touched bool) {
+	syncStore.writeKeys([]string{key}, func(store Store) { touched = store.Touch(key) })
+	return touched
+}
+
+// TouchTimestamped updates just the timestamp of the key's value; see
+// Store.TouchTimestamped.
+func (syncStore *SyncStore) TouchTimestamped(key string, timestamp int64) (touched bool) {
+	syncStore.writeKeys([]string{key}, func(store Store) { touched = store.TouchTimestamped(key, timestamp) })
+	return touched
+}
+
+// Rename is equivalent to RenameTimestamped(oldKey, newKey, Now()).
+func (syncStore *SyncStore) Rename(oldKey string, newKey string) (renamed bool) {
+	syncStore.writeKeys([]string{oldKey, newKey}, func(store Store) { renamed = store.Rename(oldKey, newKey) })
+	return renamed
+}
+
+// RenameTimestamped moves the value of oldKey to newKey; see
+// Store.RenameTimestamped.
+func (syncStore *SyncStore) RenameTimestamped(oldKey string, newKey string, timestamp int64) (renamed bool) {
+	syncStore.writeKeys([]string{oldKey, newKey}, func(store Store) { renamed = store.RenameTimestamped(oldKey, newKey, timestamp) })
+	return renamed
+}
+
+// Clear is equivalent to ClearTimestamped(Now()).
+func (syncStore *SyncStore) Clear() {
+	syncStore.write(func(store Store) { store.Clear() })
+}
+
+// ClearTimestamped records deletion markers for every key with a value; see
+// Store.ClearTimestamped.
+func (syncStore *SyncStore) ClearTimestamped(timestamp int64) {
+	syncStore.write(func(store Store) { store.ClearTimestamped(timestamp) })
+}
+
+// TransformValues replaces each value with what fn returns for it; see
+// Store.TransformValues. The write lock is held while fn is called, so fn must
+// not use syncStore.
+func (syncStore *SyncStore) TransformValues(fn func(key string, value string) string) {
+	syncStore.write(func(store Store) { store.TransformValues(fn) })
+}
+
+// Purge discards deletion markers older than the cutoff; see Store.Purge.
+func (syncStore *SyncStore) Purge(cutoff int64) {
+	syncStore.write(func(store Store) {
+		store.Purge(cutoff)
+		if cutoff > syncStore.purgeInfo.Cutoff {
+			syncStore.purgeInfo.Cutoff = cutoff
+		}
+	})
+	syncStore.lock.RLock()
+	listeners := syncStore.hooks.listeners
+	syncStore.lock.RUnlock()
+	for _, listener := range listeners {
+		if listener.purged != nil {
+			listener.purged(cutoff)
+		}
+	}
+}
+
+// Absorb updates syncStore with any newer entries from store2; see
+// Store.Absorb.
+func (syncStore *SyncStore) Absorb(store2 Store) {
+	syncStore.absorb(store2, nil, func(store Store) { store.Absorb(store2) })
+}
+
+// AbsorbAll updates syncStore with the newest entries from all of stores,
+// holding the write lock and reporting each key's Change just once; see
+// Store.AbsorbAll.
+func (syncStore *SyncStore) AbsorbAll(stores ...Store) {
+	syncStore.Absorb(newestEntries(stores))
+}
+
+// AbsorbBatched is like Absorb but releases the write lock every batchSize
+// entries; see Store.AbsorbBatched.
+func (syncStore *SyncStore) AbsorbBatched(store2 Store, batchSize int) {
+	if batchSize < 1 {
+		batchSize = 1
+	}
+	batch := make(Store, batchSize)
+	for key, valueTimestamp2 := range store2 {
+		batch[key] = valueTimestamp2
+		if len(batch) == batchSize {
+			syncStore.Absorb(batch)
+			batch = make(Store, batchSize)
+		}
+	}
+	if len(batch) > 0 {
+		syncStore.Absorb(batch)
+	}
+}
+
+// AbsorbContext is like Absorb but stops early if ctx is done; see
+// Store.AbsorbContext. The write lock is released every so often while
+// absorbing, as with AbsorbBatched.
+func (syncStore *SyncStore) AbsorbContext(ctx context.Context, store2 Store) error {
+	batch := make(Store, contextCheckInterval)
+	for key, valueTimestamp2 := range store2 {
+		batch[key] = valueTimestamp2
+		if len(batch) == contextCheckInterval {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			syncStore.Absorb(batch)
+			batch = make(Store, contextCheckInterval)
+		}
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	syncStore.Absorb(batch)
+	return nil
+}
+
+// AbsorbWithProgress is like Absorb but reports progress and may be stopped
+// early; see Store.AbsorbWithProgress. The write lock is released around each
+// call to progress, so progress may use syncStore.
+func (syncStore *SyncStore) AbsorbWithProgress(store2 Store, progress func(done int, total int) bool) bool {
+	done, total := 0, len(store2)
+	batch := make(Store, progressInterval)
+	for key, valueTimestamp2 := range store2 {
+		batch[key] = valueTimestamp2
+		done++
+		if len(batch) == progressInterval && done < total {
+			syncStore.Absorb(batch)
+			if !progress(done, total) {
+				return false
+			}
+			batch = make(Store, progressInterval)
+		}
+	}
+	syncStore.Absorb(batch)
+	return progress(done, total)
+}
+
+// AbsorbCopy is like Absorb but copies the entries; see Store.AbsorbCopy.
+func (syncStore *SyncStore) AbsorbCopy(store2 Store) {
+	syncStore.absorb(store2, nil, func(store Store) { store.AbsorbCopy(store2) })
+}
+
+// AbsorbReport is like Absorb but reports the updated and discarded keys; see
+// Store.AbsorbReport.
+func (syncStore *SyncStore) AbsorbReport(store2 Store) (updated []string, discarded []string) {
+	syncStore.absorb(store2, nil, func(store Store) { updated, discarded = store.AbsorbReport(store2) })
+	return updated, discarded
+}
+
+// AbsorbRemapped is like Absorb but renames keys found in remap; see
+// Store.AbsorbRemapped.
+func (syncStore *SyncStore) AbsorbRemapped(store2 Store, remap map[string]string) {
+	syncStore.absorb(store2, remap, func(store Store) { store.AbsorbRemapped(store2, remap) })
+}
+
+// Apply applies the batch if all the guards hold; see Store.Apply. Readers
+// will see either none or all of the batch.
+func (syncStore *SyncStore) Apply(batch *Batch, guards ...Guard) (err error) {
+	syncStore.writeKeys(batch.keys(), func(store Store) { err = store.Apply(batch, guards...) })
+	return err
+}
+
+// Copy returns a new Store holding copies of all the entries; see Store.Copy.
+func (syncStore *SyncStore) Copy() (store2 Store) {
+	syncStore.read(func(store Store) { store2 = store.Copy() })
+	return store2
+}
+
+// Filter returns a new Store of the entries pred returns true for; see
+// Store.Filter. The read lock is held while pred is called, so pred must not
+// use syncStore.
+func (syncStore *SyncStore) Filter(pred func(key string, valueTimestamp *ValueTimestamp) bool) (store2 Store) {
+	syncStore.read(func(store Store) { store2 = store.Filter(pred) })
+	return store2
+}
+
+// ModifiedSince returns a new Store of the entries newer than the timestamp;
+// see Store.ModifiedSince.
+func (syncStore *SyncStore) ModifiedSince(timestamp int64) (store2 Store) {
+	syncStore.read(func(store Store) { store2 = store.ModifiedSince(timestamp) })
+	return store2
+}
+
+// Tombstones returns the keys marked deleted; see Store.Tombstones.
+func (syncStore *SyncStore) Tombstones() (tombstones map[string]int64) {
+	syncStore.read(func(store Store) { tombstones = store.Tombstones() })
+	return tombstones
+}
+
+// OldestTimestamp returns the oldest timestamp of any entry; see
+// Store.OldestTimestamp.
+func (syncStore *SyncStore) OldestTimestamp() (oldest int64) {
+	syncStore.read(func(store Store) { oldest = store.OldestTimestamp() })
+	return oldest
+}
+
+// NewestTimestamp returns the newest timestamp of any entry; see
+// Store.NewestTimestamp.
+func (syncStore *SyncStore) NewestTimestamp() (newest int64) {
+	syncStore.read(func(store Store) { newest = store.NewestTimestamp() })
+	return newest
+}
+
+// NewestKeys returns up to n of the most recently changed keys; see
+// Store.NewestKeys.
+func (syncStore *SyncStore) NewestKeys(n int) (ks []string) {
+	syncStore.read(func(store Store) { ks = store.NewestKeys(n) })
+	return ks
+}
+
+// PrefixKeys returns the sorted keys with values beginning with prefix; see
+// Store.PrefixKeys.
+func (syncStore *SyncStore) PrefixKeys(prefix string) (ks []string) {
+	syncStore.read(func(store Store) { ks = store.PrefixKeys(prefix) })
+	return ks
+}
+
+// PrefixRange calls fn for each entry whose key begins with prefix; see
+// Store.PrefixRange. The read lock is held while fn is called, so fn must not
+// use syncStore.
+func (syncStore *SyncStore) PrefixRange(prefix string, fn func(key string, valueTimestamp *ValueTimestamp) bool) {
+	syncStore.read(func(store Store) { store.PrefixRange(prefix, fn) })
+}
+
+// RangeKeys returns the sorted keys with values in the range given; see
+// Store.RangeKeys.
+func (syncStore *SyncStore) RangeKeys(start string, end string) (ks []string) {
+	syncStore.read(func(store Store) { ks = store.RangeKeys(start, end) })
+	return ks
+}
+
+// ToMap returns the keys and values as a plain map; see Store.ToMap.
+func (syncStore *SyncStore) ToMap() (m map[string]string) {
+	syncStore.read(func(store Store) { m = store.ToMap() })
+	return m
+}
+
+// Hash returns a hash of the keys, timestamps, and flags; see Store.Hash.
+func (syncStore *SyncStore) Hash() (hash string) {
+	syncStore.read(func(store Store) { hash = store.Hash() })
+	return hash
+}
+
+// HashExcluding is like Hash but ignores keys with the prefixes given; see
+// Store.HashExcluding.
+func (syncStore *SyncStore) HashExcluding(prefixes ...string) (hash string) {
+	syncStore.read(func(store Store) { hash = store.HashExcluding(prefixes...) })
+	return hash
+}
+
+// HashContext is like Hash but gives up if ctx is done; see
+// Store.HashContext. The hash is computed from a Snapshot, so writers are not
+// held up while it runs, though the next write pays for copying the store;
+// see Snapshot.
+func (syncStore *SyncStore) HashContext(ctx context.Context) (string, error) {
+	return syncStore.Snapshot().HashContext(ctx)
+}
+
+// Verify checks the entries for consistency; see Store.Verify.
+func (syncStore *SyncStore) Verify() (errs []error) {
+	syncStore.read(func(store Store) { errs = store.Verify() })
+	return errs
+}
+
+// Census summarizes the shape of the store; see Store.Census.
+func (syncStore *SyncStore) Census(separator string, depth int, minCount int) (census *Census) {
+	syncStore.read(func(store Store) { census = store.Census(separator, depth, minCount) })
+	return census
+}
+
+// MergeSaveFile saves to the file at path after absorbing its contents; see
+// Store.MergeSaveFile.
+func (syncStore *SyncStore) MergeSaveFile(path string) (err error) {
+	syncStore.change(nil, true, func(store Store) { err = store.MergeSaveFile(path) })
+	return err
+}
+
+// SaveFile atomically writes the store to the file at path; see Store.SaveFile.
+// It saves a Snapshot, so writers are not held up while the file is written,
+// apart from the next write copying the store; see Snapshot. The store's
+// PurgeInfo, as it was before the snapshot, is then saved beside it, to
+// path+".purge", for LoadFile to restore.
+//
+// The two files are written one after the other rather than together, but in
+// an order that makes a crash between them harmless: the .purge file left
+// behind can only hold an older, lower cutoff, which just purges fewer
+// deletion markers on load.
+func (syncStore *SyncStore) SaveFile(path string) error {
+	purgeInfo := syncStore.PurgeInfo()
+	if err := syncStore.Snapshot().SaveFile(path); err != nil {
+		return err
+	}
+	b, err := json.Marshal(purgeInfo)
+	if err != nil {
+		return err
+	}
+	return writeFileAtomic(path+".purge", b)
+}
+
+// LoadFile absorbs the store saved at path, such as by SaveFile, and restores
+// the PurgeInfo saved beside it, if any. The saved cutoff is applied again
+// with Purge, so that deletion markers the store had already purged before it
+// was saved stay purged; see PurgeInfo for what this does not prevent.
+func (syncStore *SyncStore) LoadFile(path string) error {
+	store, err := LoadFile(path)
+	if err != nil {
+		return err
+	}
+	var purgeInfo PurgeInfo
+	b, err := os.ReadFile(path + ".purge")
+	if err == nil {
+		err = json.Unmarshal(b, &purgeInfo)
+	}
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%s.purge: %s", path, err)
+	}
+	syncStore.Absorb(store)
+	syncStore.lock.Lock()
+	if syncStore.purgeInfo.Retention == 0 {
+		syncStore.purgeInfo.Retention = purgeInfo.Retention
+	}
+	syncStore.lock.Unlock()
+	if purgeInfo.Cutoff != 0 {
+		syncStore.Purge(purgeInfo.Cutoff)
+	}
+	return nil
+}
+
+// PurgeInfo returns the highest cutoff passed to Purge and the retention of
+// the most recently started Purger.
+func (syncStore *SyncStore) PurgeInfo() PurgeInfo {
+	syncStore.lock.RLock()
+	defer syncStore.lock.RUnlock()
+	return syncStore.purgeInfo
+}
+
+// MarshalJSON returns the JSON encoded version of the store or an error.
+func (syncStore *SyncStore) MarshalJSON() (b []byte, err error) {
+	syncStore.read(func(store Store) { b, err = json.Marshal(store) })
+	return b, err
+}
+
+// String returns the JSON encoded string representation of the store; see
+// Store.String.
+func (syncStore *SyncStore) String() (s string) {
+	syncStore.read(func(store Store) { s = store.String() })
+	return s
+}
+
+// SimpleString returns a simple key=value[,key=value] string form of the
+// store; see Store.SimpleString.
+func (syncStore *SyncStore) SimpleString() (s string) {
+	syncStore.read(func(store Store) { s = store.SimpleString() })
+	return s
+}
+
+// SimpleStringQuoted is like SimpleString but with keys and values quoted;
+// see Store.SimpleStringQuoted.
+func (syncStore *SyncStore) SimpleStringQuoted() (s string) {
+	syncStore.read(func(store Store) { s = store.SimpleStringQuoted() })
+	return s
+}
@@ -0,0 +1,134 @@
+package kvt_test
+
+import (
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func TestSyncStoreConcurrent(t *testing.T) {
+	syncStore := &kvt.SyncStore{}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				key := fmt.Sprintf("%d-%d", i, j)
+				syncStore.SetTimestamped(key, "value", 1)
+				syncStore.Get(key)
+				syncStore.Hash()
+				other := kvt.Store{}
+				other.DeleteTimestamped(key, 2)
+				syncStore.Absorb(other)
+			}
+		}(i)
+	}
+	wg.Wait()
+	if n := len(syncStore.Tombstones()); n != 800 {
+		t.Fatal(n)
+	}
+}
+
+func TestSyncStoreAbsorbBatched(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	store2 := kvt.Store{}
+	for i := 0; i < 25; i++ {
+		store2.SetTimestamped(fmt.Sprintf("%02d", i), "value", 1)
+	}
+	syncStore.AbsorbBatched(store2, 10)
+	if keys := syncStore.RangeKeys("", ""); len(keys) != 25 {
+		t.Fatal(keys)
+	}
+}
+
+func TestSyncStoreAbsorbWithProgress(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	store2 := kvt.Store{}
+	for i := 0; i < 2500; i++ {
+		store2.SetTimestamped(fmt.Sprintf("k%d", i), "v", 1)
+	}
+	var reports []int
+	completed := syncStore.AbsorbWithProgress(store2, func(done, total int) bool {
+		// The lock is not held, so the store may be used here.
+		if n := len(syncStore.Copy()); n != done || total != 2500 {
+			t.Error(n, done, total)
+		}
+		reports = append(reports, done)
+		return done < 2048
+	})
+	if completed || fmt.Sprint(reports) != "[1024 2048]" || len(syncStore.Copy()) != 2048 {
+		t.Fatal(completed, reports, len(syncStore.Copy()))
+	}
+}
+
+func TestSyncStorePanickingCallbacks(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.OnSet(func(key string, old, new *kvt.ValueTimestamp) {})
+	mustPanic := func(fn func()) {
+		defer func() {
+			if recover() == nil {
+				t.Error("expected panic")
+			}
+		}()
+		fn()
+	}
+	mustPanic(func() {
+		syncStore.Filter(func(key string, valueTimestamp *kvt.ValueTimestamp) bool { panic(key) })
+	})
+	mustPanic(func() {
+		syncStore.TransformValues(func(key string, value string) string { panic(key) })
+	})
+	mustPanic(func() {
+		syncStore.View(func(store kvt.Store) { panic("view") })
+	})
+	// Neither lock was left held.
+	syncStore.SetTimestamped("B", "two", 2)
+	if s := syncStore.String(); s != `{"A":["one",1],"B":["two",2]}` {
+		t.Fatal(s)
+	}
+}
+
+func TestSyncStoreAbsorbAll(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.SetTimestamped("A", "old", 1)
+	var applied []string
+	syncStore.OnAbsorbApplied(func(key string, oldValueTimestamp, newValueTimestamp *kvt.ValueTimestamp) {
+		applied = append(applied, key+"="+newValueTimestamp.String())
+	})
+	peer1, peer2, peer3 := kvt.Store{}, kvt.Store{}, kvt.Store{}
+	peer1.SetTimestamped("A", "two", 2)
+	peer2.SetTimestamped("A", "three", 3)
+	peer2.SetTimestamped("B", "one", 1)
+	peer3.DeleteTimestamped("B", 2)
+	syncStore.AbsorbAll(peer1, peer2, peer3)
+	if s := fmt.Sprint(applied); s != "[A=three,3 B=nil,2]" {
+		t.Fatal(s)
+	}
+	if s := syncStore.String(); s != `{"A":["three",3],"B":[null,2]}` {
+		t.Fatal(s)
+	}
+}
+
+func TestZeroIntervals(t *testing.T) {
+	dir := t.TempDir()
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.StartPurger(0, time.Hour).Stop()
+	syncStore.WatchTombstones(-1, 0, 0, func(kvt.TombstoneStats) {}).Stop()
+	syncStore.WatchFile(filepath.Join(dir, "store.json"), 0).Stop()
+	backend := kvt.NewFileBackend(filepath.Join(dir, "backend.json"))
+	if err := syncStore.StartAutoSave(backend, 0).Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := backend.Close(); err != nil {
+		t.Fatal(err)
+	}
+	bridge := kvt.NewBridge(syncStore, "a/", kvt.NewSyncStore(nil), "b/")
+	bridge.Start(0)
+	bridge.Stop()
+}
@@ -0,0 +1,65 @@
+package kvt_test
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore() {
+	syncStore := kvt.NewSyncStore(nil)
+	var wg sync.WaitGroup
+	for i := 0; i < 3; i++ {
+		wg.Add(1)
+		go func(i int) {
+			syncStore.Set(fmt.Sprintf("worker%d", i), "done")
+			wg.Done()
+		}(i)
+	}
+	wg.Wait()
+	fmt.Println(syncStore.SimpleString())
+
+	// Output:
+	// worker0=done,worker1=done,worker2=done
+}
+
+func ExampleNewSyncStore() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", 1)
+	syncStore := kvt.NewSyncStore(store)
+	syncStore.SetTimestamped("B", "two", 2)
+	fmt.Println(syncStore)
+
+	// Output:
+	// {"A":["one",1],"B":["two",2]}
+}
+
+func ExampleSyncStore_Snapshot() {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.SetTimestamped("A", "one", 1)
+	snapshot := syncStore.Snapshot()
+	syncStore.SetTimestamped("A", "two", 2)
+	syncStore.SetTimestamped("B", "three", 2)
+	fmt.Println("Snapshot:", snapshot, snapshot.Hash())
+	fmt.Println("Current:", syncStore)
+
+	// Output:
+	// Snapshot: {"A":["one",1]} 7816aa8cb7c88f29
+	// Current: {"A":["two",2],"B":["three",2]}
+}
+
+func ExampleSyncStore_View() {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.SetTimestamped("net/mtu", "1500", 1)
+	syncStore.SetTimestamped("net/addr", "10.0.0.1", 1)
+	syncStore.View(func(store kvt.Store) {
+		// No writes can happen between these two calls.
+		fmt.Println(store.Hash())
+		fmt.Println(store.PrefixKeys("net/"))
+	})
+
+	// Output:
+	// 20cb6ea66c39e41a
+	// [net/addr net/mtu]
+}
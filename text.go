@@ -0,0 +1,30 @@
+package kvt
+
+// MarshalText implements encoding.TextMarshaler, so a Store can be embedded in
+// structs encoded by YAML, TOML, and other libraries that fall back to text;
+// the text is the JSON given by MarshalJSON.
+func (store Store) MarshalText() ([]byte, error) {
+	return store.MarshalJSON()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, reading the text as
+// UnmarshalJSON does. Empty text, as from an empty YAML scalar, leaves the
+// store unchanged.
+func (store *Store) UnmarshalText(b []byte) error {
+	if len(b) == 0 {
+		return nil
+	}
+	return store.UnmarshalJSON(b)
+}
+
+// MarshalText implements encoding.TextMarshaler; the text is the JSON given
+// by MarshalJSON.
+func (valueTimestamp *ValueTimestamp) MarshalText() ([]byte, error) {
+	return valueTimestamp.MarshalJSON()
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, reading the text as
+// UnmarshalJSON does.
+func (valueTimestamp *ValueTimestamp) UnmarshalText(b []byte) error {
+	return valueTimestamp.UnmarshalJSON(b)
+}
@@ -0,0 +1,31 @@
+package kvt_test
+
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_MarshalText() {
+	// Encoders that use encoding.TextMarshaler, such as encoding/xml here or
+	// many YAML and TOML libraries, can carry a Store as a field.
+	type Config struct {
+		Name     string    `xml:"name"`
+		Metadata kvt.Store `xml:"metadata"`
+	}
+	config := Config{Name: "web", Metadata: kvt.Store{}}
+	config.Metadata.SetTimestamped("A", "one", 1)
+	config.Metadata.DeleteTimestamped("B", 2)
+	b, err := xml.Marshal(config)
+	fmt.Println(string(b), err)
+
+	var config2 Config
+	fmt.Println(xml.Unmarshal(b, &config2))
+	fmt.Println(config2.Name, config2.Metadata)
+
+	// Output:
+	// <Config><name>web</name><metadata>{&#34;A&#34;:[&#34;one&#34;,1],&#34;B&#34;:[null,2]}</metadata></Config> <nil>
+	// <nil>
+	// web {"A":["one",1],"B":[null,2]}
+}
@@ -0,0 +1,31 @@
+package kvt
+
+import (
+	"math"
+	"time"
+)
+
+const (
+	// MinTimestamp is older than any other timestamp; for example,
+	// ModifiedSince(MinTimestamp) returns every entry.
+	MinTimestamp int64 = math.MinInt64
+	// MaxTimestamp is newer than any other timestamp; for example,
+	// Purge(MaxTimestamp) discards every deletion marker.
+	MaxTimestamp int64 = math.MaxInt64
+)
+
+// Now returns the current time as a timestamp, in nanoseconds since the Unix
+// epoch.
+func Now() int64 {
+	return time.Now().UnixNano()
+}
+
+// Timestamp returns t as a timestamp, in nanoseconds since the Unix epoch.
+func Timestamp(t time.Time) int64 {
+	return t.UnixNano()
+}
+
+// Time returns the timestamp as a time.Time.
+func Time(timestamp int64) time.Time {
+	return time.Unix(0, timestamp)
+}
@@ -0,0 +1,30 @@
+package kvt_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleTimestamp() {
+	t := time.Date(2017, 1, 2, 3, 4, 5, 6, time.UTC)
+	timestamp := kvt.Timestamp(t)
+	fmt.Println(timestamp, kvt.Time(timestamp).UTC())
+
+	// Output:
+	// 1483326245000000006 2017-01-02 03:04:05.000000006 +0000 UTC
+}
+
+func ExampleNow() {
+	store := kvt.Store{}
+	store.SetTimestamped("A", "one", kvt.Now())
+	store.DeleteTimestamped("B", kvt.Now())
+	fmt.Println(len(store.ModifiedSince(kvt.MinTimestamp)))
+	store.Purge(kvt.MaxTimestamp)
+	fmt.Println(store.SimpleString())
+
+	// Output:
+	// 2
+	// A=one
+}
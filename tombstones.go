@@ -0,0 +1,62 @@
+package kvt
+
+import (
+	"math"
+	"time"
+)
+
+// TombstoneStats are the counts checked by a TombstoneWatchdog.
+type TombstoneStats struct {
+	Live    int
+	Deleted int
+	// Ratio is Deleted divided by Live; it is +Inf if there are deletion
+	// markers but no live entries.
+	Ratio float64
+}
+
+// TombstoneStats returns the counts of live entries and deletion markers.
+func (store Store) TombstoneStats() TombstoneStats {
+	var stats TombstoneStats
+	for _, valueTimestamp := range store {
+		if valueTimestamp.Value == nil {
+			stats.Deleted++
+		} else {
+			stats.Live++
+		}
+	}
+	if stats.Live > 0 {
+		stats.Ratio = float64(stats.Deleted) / float64(stats.Live)
+	} else if stats.Deleted > 0 {
+		stats.Ratio = math.Inf(1)
+	}
+	return stats
+}
+
+// TombstoneStats returns the counts of live entries and deletion markers; see
+// Store.TombstoneStats.
+func (syncStore *SyncStore) TombstoneStats() (stats TombstoneStats) {
+	syncStore.read(func(store Store) { stats = store.TombstoneStats() })
+	return stats
+}
+
+// TombstoneWatchdog periodically checks a SyncStore's TombstoneStats; see
+// SyncStore.WatchTombstones.
+type TombstoneWatchdog struct {
+	*background
+}
+
+// WatchTombstones starts a TombstoneWatchdog that checks the store's
+// TombstoneStats every interval and calls alert whenever the ratio of
+// deletion markers to live entries exceeds maxRatio or the total number of
+// entries exceeds maxEntries; a limit of 0 is not checked. A ratio that keeps
+// climbing usually means something is deleting and recreating keys in a loop.
+// An interval of 0 or less is treated as one second. Call Stop on the
+// watchdog when it is no longer needed.
+func (syncStore *SyncStore) WatchTombstones(interval time.Duration, maxRatio float64, maxEntries int, alert func(stats TombstoneStats)) *TombstoneWatchdog {
+	return &TombstoneWatchdog{startBackground(interval, func() {
+		stats := syncStore.TombstoneStats()
+		if (maxRatio > 0 && stats.Ratio > maxRatio) || (maxEntries > 0 && stats.Live+stats.Deleted > maxEntries) {
+			alert(stats)
+		}
+	})}
+}
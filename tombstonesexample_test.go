@@ -0,0 +1,36 @@
+package kvt_test
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleStore_TombstoneStats() {
+	store := kvt.Store{}
+	store.Set("A", "one")
+	store.Set("B", "two")
+	store.Delete("C")
+	fmt.Printf("%+v\n", store.TombstoneStats())
+
+	// Output:
+	// {Live:2 Deleted:1 Ratio:0.5}
+}
+
+func ExampleSyncStore_WatchTombstones() {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.Set("A", "one")
+	for i := 0; i < 5; i++ {
+		syncStore.Delete(fmt.Sprintf("churn%d", i))
+	}
+	alerts := make(chan kvt.TombstoneStats, 10)
+	watchdog := syncStore.WatchTombstones(time.Millisecond, 2, 0, func(stats kvt.TombstoneStats) {
+		alerts <- stats
+	})
+	fmt.Printf("%+v\n", <-alerts)
+	watchdog.Stop()
+
+	// Output:
+	// {Live:1 Deleted:5 Ratio:5}
+}
@@ -0,0 +1,121 @@
+package kvt
+
+import (
+	"errors"
+	"sync"
+)
+
+// WAL is a write-ahead log for a SyncStore: every write that changes the
+// store has its changes appended to a Backend, with AppendDelta, before the
+// write returns, so the store can be rebuilt after a crash by loading it from
+// the Backend. See SyncStore.OpenWAL.
+//
+// Purge is not logged, as purged deletion markers aren't changes; a store
+// rebuilt from the Backend has any deletion markers purged since the last
+// Compact, and no PurgeInfo. Purge again after OpenWAL, such as with
+// PurgeInfo saved by SyncStore.SaveFile, to discard them.
+//
+// To keep the deltas from growing forever, Compact saves the whole store to
+// the Backend, which discards the deltas; this can also be done automatically
+// with SetCompactThreshold. Compact saves a Snapshot, so the write after it
+// copies the whole store; see SyncStore.Snapshot.
+type WAL struct {
+	syncStore *SyncStore
+	backend   Backend
+	lock      sync.Mutex
+	closed    bool
+	entries   int
+	threshold int
+	err       error
+	// stopListening unregisters changed from the SyncStore.
+	stopListening func()
+}
+
+// OpenWAL loads the store from backend into syncStore and then appends to
+// backend every subsequent change to syncStore. The caller remains
+// responsible for closing backend, after closing the WAL.
+func (syncStore *SyncStore) OpenWAL(backend Backend) (*WAL, error) {
+	store, err := backend.Load()
+	if err != nil {
+		return nil, err
+	}
+	syncStore.Absorb(store)
+	wal := &WAL{syncStore: syncStore, backend: backend}
+	wal.stopListening = syncStore.listen(&listener{changed: wal.changed})
+	return wal, nil
+}
+
+// changed is the SyncStore hook for each batch of changes.
+func (wal *WAL) changed(changes []Change) {
+	delta := make(Store, len(changes))
+	for _, change := range changes {
+		delta[change.Key] = change.New
+	}
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	if wal.closed || wal.err != nil {
+		return
+	}
+	if wal.err = wal.backend.AppendDelta(delta); wal.err != nil {
+		return
+	}
+	wal.entries += len(delta)
+	if wal.threshold > 0 && wal.entries > wal.threshold {
+		wal.err = wal.compact()
+	}
+}
+
+// SetCompactThreshold has the WAL Compact automatically whenever more than n
+// entries have been appended since the last compaction; 0, the default,
+// disables automatic compaction. Compaction is done by whichever write crosses
+// the threshold, before that write returns.
+func (wal *WAL) SetCompactThreshold(n int) {
+	wal.lock.Lock()
+	wal.threshold = n
+	wal.lock.Unlock()
+}
+
+// Compact saves a Snapshot of the store to the Backend, discarding the deltas
+// appended so far.
+func (wal *WAL) Compact() error {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	if wal.err != nil {
+		return wal.err
+	}
+	if wal.closed {
+		return errors.New("kvt: WAL is closed")
+	}
+	wal.err = wal.compact()
+	return wal.err
+}
+
+// compact does the work of Compact; the lock must be held. Writes made to the
+// store while compacting that aren't in the snapshot are appended once the
+// lock is released.
+func (wal *WAL) compact() error {
+	if err := wal.backend.Save(wal.syncStore.Snapshot()); err != nil {
+		return err
+	}
+	wal.entries = 0
+	return nil
+}
+
+// Err returns the first error encountered while appending to the Backend, if
+// any. After an error, nothing more is appended, as the Backend could no
+// longer be trusted to be complete.
+func (wal *WAL) Err() error {
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	return wal.err
+}
+
+// Close stops appending to the Backend, unregistering the WAL from the
+// SyncStore, and returns the first error encountered, if any.
+func (wal *WAL) Close() error {
+	wal.stopListening()
+	wal.lock.Lock()
+	defer wal.lock.Unlock()
+	wal.closed = true
+	return wal.err
+}
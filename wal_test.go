@@ -0,0 +1,108 @@
+package kvt_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestWALReplayAfterTornWrite(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	backend := kvt.NewFileBackend(path)
+	syncStore := kvt.NewSyncStore(nil)
+	wal, err := syncStore.OpenWAL(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.SetTimestamped("B", "two", 1)
+	syncStore.DeleteTimestamped("B", 2)
+	if err = wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = backend.Close(); err != nil {
+		t.Fatal(err)
+	}
+	// Simulate a crash part way through appending a line.
+	f, err := os.OpenFile(path+".journal", os.O_WRONLY|os.O_APPEND, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.WriteString(`{"C":["thr`)
+	f.Close()
+
+	backend = kvt.NewFileBackend(path)
+	syncStore = kvt.NewSyncStore(nil)
+	wal, err = syncStore.OpenWAL(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := syncStore.String(); s != `{"A":["one",1],"B":[null,2]}` {
+		t.Fatal(s)
+	}
+	syncStore.SetTimestamped("C", "three", 3)
+	if err = wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err = backend.Close(); err != nil {
+		t.Fatal(err)
+	}
+	store, err := kvt.NewFileBackend(path).Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s := store.String(); s != `{"A":["one",1],"B":[null,2],"C":["three",3]}` {
+		t.Fatal(s)
+	}
+}
+
+func TestWALCorrupt(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	if err := os.WriteFile(path+".journal", []byte("{\"A\":[\"one\",1]}\nnot json\n"), 0666); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := kvt.NewSyncStore(nil).OpenWAL(kvt.NewFileBackend(path)); err == nil {
+		t.Fatal("expected error")
+	}
+}
+
+func TestWALCompact(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "store.json")
+	backend := kvt.NewFileBackend(path)
+	defer backend.Close()
+	syncStore := kvt.NewSyncStore(nil)
+	wal, err := syncStore.OpenWAL(backend)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wal.SetCompactThreshold(5)
+	for i := int64(1); i <= 20; i++ {
+		syncStore.SetTimestamped("A", "value", i)
+	}
+	if info, err := os.Stat(path + ".journal"); err != nil || info.Size() > 100 {
+		t.Fatal(info.Size(), err)
+	}
+	syncStore.DeleteTimestamped("B", 30)
+	if err = wal.Compact(); err != nil {
+		t.Fatal(err)
+	}
+	if info, err := os.Stat(path + ".journal"); err != nil || info.Size() != 0 {
+		t.Fatal(info.Size(), err)
+	}
+	syncStore.SetTimestamped("C", "three", 31)
+	if err = wal.Close(); err != nil {
+		t.Fatal(err)
+	}
+	expected := `{"A":["value",20],"B":[null,30],"C":["three",31]}`
+	store, err := backend.Load()
+	if err != nil || store.String() != expected {
+		t.Fatal(store, err)
+	}
+	// The saved store by itself is a plain JSON file.
+	store, err = kvt.LoadFile(path)
+	if err != nil || store.String() != `{"A":["value",20],"B":[null,30]}` {
+		t.Fatal(store, err)
+	}
+}
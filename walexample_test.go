@@ -0,0 +1,44 @@
+package kvt_test
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore_OpenWAL() {
+	dir, err := os.MkdirTemp("", "kvt")
+	if err != nil {
+		panic(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "store.json")
+	backend := kvt.NewFileBackend(path)
+
+	syncStore := kvt.NewSyncStore(nil)
+	wal, err := syncStore.OpenWAL(backend)
+	if err != nil {
+		panic(err)
+	}
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.Absorb(kvt.Store{"B": {Timestamp: 2}})
+	b, _ := os.ReadFile(path + ".journal")
+	fmt.Print(string(b))
+	fmt.Println(wal.Close(), backend.Close())
+
+	// After a restart, opening the WAL again restores the store.
+	backend = kvt.NewFileBackend(path)
+	syncStore = kvt.NewSyncStore(nil)
+	wal, err = syncStore.OpenWAL(backend)
+	fmt.Println(syncStore, err)
+	wal.Close()
+	backend.Close()
+
+	// Output:
+	// {"A":["one",1]}
+	// {"B":[null,2]}
+	// <nil> <nil>
+	// {"A":["one",1],"B":[null,2]} <nil>
+}
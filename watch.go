@@ -0,0 +1,91 @@
+package kvt
+
+import "sync"
+
+// Change describes an entry of a SyncStore that was set, deleted, or updated
+// by an Absorb.
+type Change struct {
+	Key string
+	// Old is a copy of the entry before the change, or nil if the key had no
+	// entry.
+	Old *ValueTimestamp
+	// New is a copy of the entry after the change.
+	New *ValueTimestamp
+	// Absorbed is true if the change came from an Absorb, or from merging
+	// with a file's contents, rather than from a local write.
+	Absorbed bool
+}
+
+// Watch returns a channel that receives a Change for each entry with a key
+// beginning with prefix that is changed from then on, in the order the
+// changes were made; calling the cancel function returned stops the watch and
+// closes the channel. Writes never wait on a slow receiver; changes are queued
+// until received. Entries removed by Purge are not reported.
+func (syncStore *SyncStore) Watch(prefix string) (<-chan Change, func()) {
+	w := &watcher{
+		prefix: prefix,
+		notify: make(chan struct{}, 1),
+		done:   make(chan struct{}),
+		out:    make(chan Change),
+	}
+	syncStore.lock.Lock()
+	if syncStore.watchers == nil {
+		syncStore.watchers = map[*watcher]bool{}
+	}
+	syncStore.watchers[w] = true
+	syncStore.lock.Unlock()
+	go w.run()
+	var once sync.Once
+	return w.out, func() {
+		once.Do(func() {
+			syncStore.lock.Lock()
+			delete(syncStore.watchers, w)
+			syncStore.lock.Unlock()
+			close(w.done)
+		})
+	}
+}
+
+// watcher queues the changes for one Watch and forwards them to its channel.
+type watcher struct {
+	prefix string
+	lock   sync.Mutex
+	queue  []Change
+	notify chan struct{}
+	done   chan struct{}
+	out    chan Change
+}
+
+// push queues the change without waiting for it to be received.
+func (watcher *watcher) push(change Change) {
+	watcher.lock.Lock()
+	watcher.queue = append(watcher.queue, change)
+	watcher.lock.Unlock()
+	select {
+	case watcher.notify <- struct{}{}:
+	default:
+	}
+}
+
+// run forwards queued changes until the watch is canceled.
+func (watcher *watcher) run() {
+	defer close(watcher.out)
+	for {
+		watcher.lock.Lock()
+		queue := watcher.queue
+		watcher.queue = nil
+		watcher.lock.Unlock()
+		for _, change := range queue {
+			select {
+			case watcher.out <- change:
+			case <-watcher.done:
+				return
+			}
+		}
+		select {
+		case <-watcher.notify:
+		case <-watcher.done:
+			return
+		}
+	}
+}
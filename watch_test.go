@@ -0,0 +1,42 @@
+package kvt_test
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/gholt/kvt"
+)
+
+func TestWatchBulkChanges(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	syncStore.SetTimestamped("A", "one", 1)
+	syncStore.SetTimestamped("B", "two", 1)
+	changes, cancel := syncStore.Watch("")
+	defer cancel()
+	syncStore.ClearTimestamped(2)
+	syncStore.Purge(3)
+	syncStore.SetTimestamped("C", "three", 4)
+	var got []string
+	for i := 0; i < 3; i++ {
+		change := <-changes
+		got = append(got, fmt.Sprint(change.Key, " ", change.New))
+	}
+	if fmt.Sprint(got) != "[A nil,2 B nil,2 C three,4]" {
+		t.Fatal(got)
+	}
+}
+
+func TestWatchSlowReceiver(t *testing.T) {
+	syncStore := kvt.NewSyncStore(nil)
+	changes, cancel := syncStore.Watch("")
+	for i := 0; i < 1000; i++ {
+		syncStore.SetTimestamped(fmt.Sprintf("%04d", i), "v", 1)
+	}
+	for i := 0; i < 1000; i++ {
+		if change := <-changes; change.Key != fmt.Sprintf("%04d", i) {
+			t.Fatal(change.Key)
+		}
+	}
+	cancel()
+	cancel()
+}
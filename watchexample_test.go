@@ -0,0 +1,32 @@
+package kvt_test
+
+import (
+	"fmt"
+
+	"github.com/gholt/kvt"
+)
+
+func ExampleSyncStore_Watch() {
+	syncStore := kvt.NewSyncStore(nil)
+	changes, cancel := syncStore.Watch("net/")
+	syncStore.SetTimestamped("net/mtu", "1500", 1)
+	syncStore.SetTimestamped("disk/size", "1T", 1) // Not watched.
+	syncStore.SetTimestamped("net/mtu", "1500", 1) // Not a change.
+	remote := kvt.Store{}
+	remote.SetTimestamped("net/mtu", "9000", 2)
+	remote.DeleteTimestamped("net/addr", 2)
+	syncStore.Absorb(remote)
+	for i := 0; i < 3; i++ {
+		change := <-changes
+		fmt.Println(change.Key, change.Old, change.New, change.Absorbed)
+	}
+	cancel()
+	_, open := <-changes
+	fmt.Println(open)
+
+	// Output:
+	// net/mtu <nil> 1500,1 false
+	// net/addr <nil> nil,2 true
+	// net/mtu 1500,1 9000,2 true
+	// false
+}